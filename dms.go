@@ -24,6 +24,18 @@ import (
 var (
 	// /[^0-9., ]+/
 	separatorChars = regexp.MustCompile(`[^0-9.]+`)
+
+	// normalises the Unicode minus sign, and the various degree / prime / double-prime glyphs
+	// commonly found in copied coordinates, into their plain ASCII equivalents
+	dmsSymbols = strings.NewReplacer(
+		"−", "-", // U+2212 minus sign
+		"°", " ", "º", " ", // degree, masculine ordinal
+		"′", " ", "'", " ", "’", " ", "‘", " ", "`", " ", "´", " ", // primes
+		"″", " ", `"`, " ", "“", " ", "”", " ", // double primes
+	)
+
+	// a unit written out after the (last) number, as in "51.5deg" or "51.5 degrees N"
+	degreesSuffix = regexp.MustCompile(`([0-9.])\s*(?i:deg(?:rees?)?)(\s*[NSEW]?\s*)$`)
 )
 
  // Wrap90 constrains degrees to range -90..+90 (for latitude); e.g. -91 => -89, 91 => 89.
@@ -66,8 +78,23 @@ func Wrap180(degrees float64) float64 {
 	return math.Mod((math.Mod(2*a*x/p-p/2, p))+p, p) - a
 }
 
-// Wrap360 constrain degrees to range 0..360 (for bearings); e.g. -1 => 359, 361 => 1.
+// Wrap180Canonical is equivalent to Wrap180, but always gives the anti-meridian as +180 (never
+// -180), so that the result is in the range (-180, +180] and each longitude has a single
+// representation; e.g. -180 => 180, 540 => 180.
+func Wrap180Canonical(degrees float64) float64 {
+	degrees = Wrap180(degrees)
+	if degrees == -180 {
+		return 180
+	}
+	return degrees
+}
+
+// Wrap360 constrain degrees to range 0..360 (for bearings); e.g. -1 => 359, 361 => 1. 360 itself,
+// and any multiple of it, gives 0, as does -0.
 func Wrap360(degrees float64) float64 {
+	if degrees == 0 {
+		return 0 // including -0
+	}
 	// avoid rounding due to arithmetic ops if within range
 	if 0 <= degrees && degrees < 360 {
 		return degrees
@@ -88,28 +115,71 @@ func Wrap360(degrees float64) float64 {
 	return math.Mod((math.Mod(2*a*x/p, p))+p, p)
 }
 
+// Wrap90All applies Wrap90 to each of the degrees in place.
+func Wrap90All(degrees []float64) {
+	for i, d := range degrees {
+		if d < -90 || d > 90 {
+			degrees[i] = Wrap90(d)
+		}
+	}
+}
+
+// Wrap180All applies Wrap180 to each of the degrees in place.
+func Wrap180All(degrees []float64) {
+	for i, d := range degrees {
+		if d < -180 || d > 180 {
+			degrees[i] = Wrap180(d)
+		}
+	}
+}
+
+// Wrap360All applies Wrap360 to each of the degrees in place.
+func Wrap360All(degrees []float64) {
+	for i, d := range degrees {
+		if d <= 0 || d >= 360 {
+			degrees[i] = Wrap360(d)
+		}
+	}
+}
+
 func invalid(s string) error {
-	return fmt.Errorf("invalid degree: '%s'", s)
+	return fmt.Errorf("%w: '%s'", ErrInvalidDegrees, s)
 }
 
- // ParseDegrees parses a string representing degrees/minutes/seconds into numeric degrees.
- //
- // This is very flexible on formats, allowing signed decimal degrees, or deg-min-sec optionally
- // suffixed by compass direction (NSEW); a variety of separators are accepted. Examples -3.62,
- // '3 37 12W', '3°37′12″W'.
- //
- // Thousands/decimal separators must be comma/dot; use Dms.fromLocale to convert locale-specific
- // thousands/decimal separators.
- //
- // example
- //   lat = ParseDegrees(`51° 28′ 40.37″ N`);
- //   lon = ParseDegrees(`000° 00′ 05.29″ W`);
+// ParseDegrees parses a string representing degrees/minutes/seconds into numeric degrees.
+//
+// This is very flexible on formats, allowing signed decimal degrees, or deg-min-sec optionally
+// suffixed by compass direction (NSEW); a variety of separators are accepted. Examples -3.62,
+// '3 37 12W', '3°37′12″W'. The Unicode minus sign (U+2212) is treated as '-', and the various
+// degree, prime and double-prime glyphs (° ′ ″ ' " ` ´ and curly quotes) as separators.
+//
+// The accepted grammar, ignoring leading and trailing white space, is:
+//   [sign] degrees [sep minutes [sep seconds]] [sep] [compass]
+// where sign is '+' or '-', compass is one of 'N', 'S', 'E' or 'W', each of degrees, minutes
+// and seconds is an unsigned decimal number, and sep is any run of characters other than digits
+// and '.'. A plain signed number as accepted by strconv.ParseFloat (without compass direction) is
+// also accepted. Anything else, including NaN and infinities, is rejected with an error and a
+// value of 0.
+//
+// A trailing unit of "deg", "degree" or "degrees" (in any case), optionally followed by the compass
+// direction, is ignored, as in "51.5deg" or "51.5 degrees N".
+//
+// Thousands/decimal separators must be comma/dot; use Dms.fromLocale to convert locale-specific
+// thousands/decimal separators.
+//
+// example
+//   lat = ParseDegrees(`51° 28′ 40.37″ N`);
+//   lon = ParseDegrees(`000° 00′ 05.29″ W`);
 func ParseDegrees(s string) (float64, error) {
 	orig := s
-	s = strings.TrimSpace(s)
+	s = strings.TrimSpace(dmsSymbols.Replace(s))
+	s = degreesSuffix.ReplaceAllString(s, "$1$2")
 	// check for signed decimal degrees without NSEW, if so return it directly
 	f, err := strconv.ParseFloat(s, 64)
 	if err == nil {
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, invalid(orig)
+		}
 		return f, nil
 	}
 
@@ -141,7 +211,11 @@ func ParseDegrees(s string) (float64, error) {
 		return 0, invalid(orig)
 	}
 	if dmsParts[len(dmsParts)-1] == "" {
-		dmsParts=dmsParts[:len(dmsParts)-1]
+		dmsParts = dmsParts[:len(dmsParts)-1]
+	}
+	// at most degrees, minutes and seconds
+	if len(dmsParts) > 3 {
+		return 0, invalid(orig)
 	}
 	multiplier := 1.0
 	sum := 0.0
@@ -159,3 +233,129 @@ func ParseDegrees(s string) (float64, error) {
 	}
 	return sum, nil
 }
+
+// ParseNMEACoordinate converts a latitude or longitude field from an NMEA 0183 sentence, in the
+// form ddmm.mmmm (latitude) or dddmm.mmmm (longitude), to signed decimal degrees. The last two
+// digits before the decimal point (and any decimal fraction) are the minutes, and any preceding
+// digits are the degrees. The hemisphere is the separate NMEA indicator field: 'N' or 'E' for
+// positive values, 'S' or 'W' for negative.
+//
+// An error wrapping ErrInvalidDegrees is returned if the fields are malformed, and one wrapping
+// ErrOutOfRange if the resulting latitude or longitude is out of range.
+//
+// example
+//   lat, err := ParseNMEACoordinate("5128.6632", "N")   // 51.47772
+//   lon, err := ParseNMEACoordinate("00008.8200", "W")  // -0.147
+func ParseNMEACoordinate(value string, hemisphere string) (float64, error) {
+	value = strings.TrimSpace(value)
+	intPart := value
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		intPart = value[:i]
+	}
+	if len(intPart) < 3 || strings.Trim(intPart, "0123456789") != "" {
+		return 0, fmt.Errorf("%w: NMEA coordinate '%s'", ErrInvalidDegrees, value)
+	}
+
+	deg, err := strconv.Atoi(intPart[:len(intPart)-2])
+	if err != nil {
+		return 0, fmt.Errorf("%w: NMEA coordinate '%s'", ErrInvalidDegrees, value)
+	}
+	min, err := strconv.ParseFloat(value[len(intPart)-2:], 64)
+	if err != nil || min >= 60 {
+		return 0, fmt.Errorf("%w: NMEA coordinate '%s'", ErrInvalidDegrees, value)
+	}
+
+	d := float64(deg) + min/60
+	limit := 180.0
+	switch strings.ToUpper(strings.TrimSpace(hemisphere)) {
+	case "N":
+		limit = 90
+	case "S":
+		limit = 90
+		d = -d
+	case "E":
+	case "W":
+		d = -d
+	default:
+		return 0, fmt.Errorf("%w: NMEA hemisphere '%s'", ErrInvalidDegrees, hemisphere)
+	}
+	if math.Abs(d) > limit {
+		return 0, fmt.Errorf("%w: NMEA coordinate %s%s", ErrOutOfRange, value, hemisphere)
+	}
+	return d, nil
+}
+
+// toDMS formats unsigned degrees (the sign of deg is ignored) as degrees ("d"), degrees+minutes
+// ("dm") or degrees+minutes+seconds ("dms") to dp decimal places, with degrees padded to 3
+// digits and minutes & seconds to 2. With symbols the parts are marked °, ′ and ″; without, they
+// are separated by spaces.
+func toDMS(deg float64, format string, dp int, symbols bool) string {
+	deg = math.Abs(deg)
+	degSym, minSym, secSym, sep := "°", "′", "″", ""
+	if !symbols {
+		degSym, minSym, secSym, sep = "", "", "", " "
+	}
+
+	pad := func(f float64, width int) string {
+		s := strconv.FormatFloat(f, 'f', dp, 64)
+		if n := width - len(strings.SplitN(s, ".", 2)[0]); n > 0 {
+			s = strings.Repeat("0", n) + s
+		}
+		return s
+	}
+
+	switch format {
+	case "dm":
+		min, _ := strconv.ParseFloat(strconv.FormatFloat(deg*60, 'f', dp, 64), 64) // round to dp
+		d := math.Floor(min / 60)
+		m := math.Mod(min, 60)
+		return fmt.Sprintf("%03d%s%s%s%s", int(d), degSym, sep, pad(m, 2), minSym)
+	case "dms":
+		sec, _ := strconv.ParseFloat(strconv.FormatFloat(deg*3600, 'f', dp, 64), 64) // round to dp
+		d := math.Floor(sec / 3600)
+		m := math.Mod(math.Floor(sec/60), 60)
+		s := math.Mod(sec, 60)
+		return fmt.Sprintf("%03d%s%s%02d%s%s%s%s", int(d), degSym, sep, int(m), minSym, sep, pad(s, 2), secSym)
+	default: // "d"
+		return pad(deg, 3) + degSym
+	}
+}
+
+// dmsParts splits the magnitude of deg into whole degrees, whole minutes and seconds. The seconds
+// are rounded to the microsecond before splitting, so that rounding error such as 59.9999999″
+// carries into the minutes (and degrees) rather than being left as not-quite 60 seconds.
+func dmsParts(deg float64) (d, m int, s float64) {
+	sec := math.Round(math.Abs(deg)*3600*1e6) / 1e6
+	d = int(sec / 3600)
+	m = int(sec/60) % 60
+	s = sec - float64(d*3600+m*60)
+	return d, m, s
+}
+
+// toLatDMS formats degrees as a latitude, e.g. 51°28′40″N (with degrees padded to 2 digits).
+func toLatDMS(deg float64, format string, dp int, symbols bool) string {
+	deg = Wrap90(deg)
+	hemisphere := "N"
+	if deg < 0 {
+		hemisphere = "S"
+	}
+	s := toDMS(deg, format, dp, symbols)[1:]
+	if !symbols {
+		return s + " " + hemisphere
+	}
+	return s + hemisphere
+}
+
+// toLonDMS formats degrees as a longitude, e.g. 000°00′05″W (with degrees padded to 3 digits).
+func toLonDMS(deg float64, format string, dp int, symbols bool) string {
+	deg = Wrap180(deg)
+	hemisphere := "E"
+	if deg < 0 {
+		hemisphere = "W"
+	}
+	s := toDMS(deg, format, dp, symbols)
+	if !symbols {
+		return s + " " + hemisphere
+	}
+	return s + hemisphere
+}
@@ -0,0 +1,32 @@
+//go:build go1.18
+// +build go1.18
+
+package osgridref
+
+import (
+	"math"
+	"testing"
+)
+
+func FuzzParseDegrees(f *testing.F) {
+	seeds := []string{
+		"0.0", "0°", "000°00′00.0″", "45°45.756′", `45° 45’ 45.36"`, "45.76260W", "+45.76260",
+		"", "    ", "7.2.1", "7..18", "--45", "−45", "°′″", "-", "N", "-S", "NaN", "Inf", "1 2 3 4",
+	}
+	for _, s := range seeds {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		got, err := ParseDegrees(s)
+		if err != nil {
+			if got != 0 {
+				t.Errorf("ParseDegrees(%q) returned %v with error %v", s, got, err)
+			}
+			return
+		}
+		if math.IsNaN(got) || math.IsInf(got, 0) {
+			t.Errorf("ParseDegrees(%q) returned non-finite %v", s, got)
+		}
+	})
+}
@@ -1,6 +1,11 @@
 package osgridref
 
-import "testing"
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
 
 func TestParse(t *testing.T) {
 	tests := []struct {
@@ -24,10 +29,32 @@ func TestParse(t *testing.T) {
 		{name: "45.76260W", want: -45.76260, wantErr: false},
 		{name: "-45.76260", want: -45.76260, wantErr: false},
 		{name: "+45.76260", want: +45.76260, wantErr: false},
+		{name: "−45.76260", want: -45.76260, wantErr: false},
+		{name: "−45° 45′ 45.36″", want: -45.76260, wantErr: false},
+		{name: "45° 45′ 45.36″", want: 45.76260, wantErr: false},
+		{name: "45° 45‘ 45.36”", want: 45.76260, wantErr: false},
+		{name: "45° 45' 45.36\"", want: 45.76260, wantErr: false},
+		{name: "45º 45´ 45.36``", want: 45.76260, wantErr: false},
+		{name: "45° 45′ 45.36″ S", want: -45.76260, wantErr: false},
+		{name: "51.5deg", want: 51.5, wantErr: false},
+		{name: "51.5 degrees N", want: 51.5, wantErr: false},
+		{name: "51.5 Degrees S", want: -51.5, wantErr: false},
+		{name: "51.5DEG", want: 51.5, wantErr: false},
+		{name: "-0.5 degree", want: -0.5, wantErr: false},
+		{name: "0.5degW", want: -0.5, wantErr: false},
+		{name: "45° 45′ 45.36 degrees", want: 45.76260, wantErr: false},
+		{name: "deg", wantErr: true},
 		{name: "", wantErr: true},
 		{name: "    ", wantErr: true},
 		{name: "7.2.1", wantErr: true},
 		{name: "7..18", wantErr: true},
+		{name: "--45", wantErr: true},
+		{name: "°′″", wantErr: true},
+		{name: "-", wantErr: true},
+		{name: "N", wantErr: true},
+		{name: "NaN", wantErr: true},
+		{name: "-Inf", wantErr: true},
+		{name: "1 2 3 4", wantErr: true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -42,3 +69,155 @@ func TestParse(t *testing.T) {
 		})
 	}
 }
+
+func TestWrapAll(t *testing.T) {
+	tests := []struct {
+		name string
+		wrap func([]float64)
+		in   []float64
+		want []float64
+	}{
+		{name: "Wrap90All", wrap: Wrap90All, in: []float64{0, 45, -90, 90, 91, -91, 180, 270}, want: []float64{0, 45, -90, 90, 89, -89, 0, -90}},
+		{name: "Wrap180All", wrap: Wrap180All, in: []float64{0, 90, -180, 180, 181, -181, 360, 540}, want: []float64{0, 90, -180, 180, -179, 179, 0, -180}},
+		{name: "Wrap360All", wrap: Wrap360All, in: []float64{0, 90, -1, 361, 359, 360, -360, 720}, want: []float64{0, 90, 359, 1, 359, 0, 0, 0}},
+		{name: "empty", wrap: Wrap360All, in: []float64{}, want: []float64{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			degrees := tt.in
+			tt.wrap(degrees)
+			for i := range tt.want {
+				if degrees[i] != tt.want[i] {
+					t.Errorf("%s()[%d] got = %v, want %v", tt.name, i, degrees[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func benchmarkAngles() []float64 {
+	degrees := make([]float64, 10_000)
+	for i := range degrees {
+		degrees[i] = float64(i%1080) - 360 // -360..719, two thirds out of 0..360 range
+	}
+	return degrees
+}
+
+func BenchmarkWrap360All(b *testing.B) {
+	in := benchmarkAngles()
+	degrees := make([]float64, len(in))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(degrees, in)
+		Wrap360All(degrees)
+	}
+}
+
+func BenchmarkWrap360Loop(b *testing.B) {
+	in := benchmarkAngles()
+	degrees := make([]float64, len(in))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(degrees, in)
+		for j := range degrees {
+			degrees[j] = Wrap360(degrees[j])
+		}
+	}
+}
+
+func TestWrap360(t *testing.T) {
+	tests := []struct {
+		degrees float64
+		want    float64
+	}{
+		{degrees: 0, want: 0},
+		{degrees: math.Copysign(0, -1), want: 0},
+		{degrees: 359.9999, want: 359.9999},
+		{degrees: 360, want: 0},
+		{degrees: 720, want: 0},
+		{degrees: -360, want: 0},
+		{degrees: -720, want: 0},
+		{degrees: -1, want: 359},
+		{degrees: 361, want: 1},
+		{degrees: -1e-15, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.FormatFloat(tt.degrees, 'g', -1, 64), func(t *testing.T) {
+			got := Wrap360(tt.degrees)
+			if got != tt.want || math.Signbit(got) {
+				t.Errorf("Wrap360() got = %v, want %v", got, tt.want)
+			}
+
+			all := []float64{tt.degrees}
+			Wrap360All(all)
+			if all[0] != got || math.Signbit(all[0]) {
+				t.Errorf("Wrap360All() got = %v, want %v", all[0], got)
+			}
+		})
+	}
+}
+
+func TestWrap180Canonical(t *testing.T) {
+	tests := []struct {
+		degrees float64
+		want    float64
+	}{
+		{degrees: 0, want: 0},
+		{degrees: 90, want: 90},
+		{degrees: 180, want: 180},
+		{degrees: -180, want: 180},
+		{degrees: 540, want: 180},
+		{degrees: -540, want: 180},
+		{degrees: 181, want: -179},
+		{degrees: -179.9999, want: -179.9999},
+		{degrees: 360, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.FormatFloat(tt.degrees, 'g', -1, 64), func(t *testing.T) {
+			if got := Wrap180Canonical(tt.degrees); got != tt.want {
+				t.Errorf("Wrap180Canonical() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNMEACoordinate(t *testing.T) {
+	tests := []struct {
+		value      string
+		hemisphere string
+		want       float64
+		wantErr    error
+	}{
+		{value: "5128.6632", hemisphere: "N", want: 51.47772},
+		{value: "00008.8200", hemisphere: "W", want: -0.147},
+		{value: "3352.1200", hemisphere: "S", want: -33.868667},
+		{value: "15112.0000", hemisphere: "e", want: 151.2},
+		{value: "4530", hemisphere: "N", want: 45.5},
+		{value: "9000.0000", hemisphere: "N", want: 90},
+		{value: "9100.0000", hemisphere: "N", wantErr: ErrOutOfRange},
+		{value: "18030.0000", hemisphere: "E", wantErr: ErrOutOfRange},
+		{value: "5160.0000", hemisphere: "N", wantErr: ErrInvalidDegrees},
+		{value: "5128.6632", hemisphere: "X", wantErr: ErrInvalidDegrees},
+		{value: "28.6632", hemisphere: "N", wantErr: ErrInvalidDegrees},
+		{value: "-5128.6632", hemisphere: "N", wantErr: ErrInvalidDegrees},
+		{value: "51x8.6632", hemisphere: "N", wantErr: ErrInvalidDegrees},
+		{value: "", hemisphere: "N", wantErr: ErrInvalidDegrees},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value+","+tt.hemisphere, func(t *testing.T) {
+			got, err := ParseNMEACoordinate(tt.value, tt.hemisphere)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("ParseNMEACoordinate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseNMEACoordinate() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("ParseNMEACoordinate() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
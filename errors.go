@@ -0,0 +1,20 @@
+package osgridref
+
+import "errors"
+
+// Errors returned by the package, wrapped with details of the offending value. Use errors.Is to
+// test for them.
+var (
+	// ErrInvalidGridRef is returned when a string cannot be parsed as an OS grid reference.
+	ErrInvalidGridRef = errors.New("invalid grid reference")
+
+	// ErrInvalidDegrees is returned when a string cannot be parsed as degrees, or as a lat/lon.
+	ErrInvalidDegrees = errors.New("invalid degrees")
+
+	// ErrUnknownDatum is returned when a datum is requested by a name not in Datums.
+	ErrUnknownDatum = errors.New("unknown datum")
+
+	// ErrOutOfRange is returned when a value is well-formed but outside its valid range, such as a
+	// grid reference off the National Grid or a latitude beyond ±90°.
+	ErrOutOfRange = errors.New("out of range")
+)
@@ -0,0 +1,57 @@
+package osgridref
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrors(t *testing.T) {
+	parseGridRef := func(s string) error {
+		_, err := ParseOsGridRef(s)
+		return err
+	}
+	parseDegrees := func(s string) error {
+		_, err := ParseDegrees(s)
+		return err
+	}
+	parseLatLon := func(s string) error {
+		_, err := ParseLatLon(s, 0, WGS84)
+		return err
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "grid ref format", err: parseGridRef("TL 123 45X"), want: ErrInvalidGridRef},
+		{name: "grid ref letters", err: parseGridRef("IT 123 456"), want: ErrInvalidGridRef},
+		{name: "grid ref digits", err: parseGridRef("TL 12345 6789"), want: ErrInvalidGridRef},
+		{name: "grid ref too many digits", err: parseGridRef("TL 123456 123456"), want: ErrInvalidGridRef},
+		{name: "tetrad", err: parseGridRef("IT12A"), want: ErrInvalidGridRef},
+		{name: "degrees", err: parseDegrees("north"), want: ErrInvalidDegrees},
+		{name: "lat/lon", err: parseLatLon("52.2, east"), want: ErrInvalidDegrees},
+		{name: "lat/lon unsplittable", err: parseLatLon("52.2"), want: ErrInvalidDegrees},
+		{name: "unknown datum", err: func() error { _, err := DatumByName("GDA94"); return err }(), want: ErrUnknownDatum},
+		{name: "grid ref outside grid", err: func() error { _, err := NewOsGridRef(-1, 0); return err }(), want: ErrOutOfRange},
+		{name: "lat/lon outside grid", err: func() error { _, err := paris.ToGridReference(100); return err }(), want: ErrOutOfRange},
+		{name: "latitude", err: func() error { _, err := cambridge.DistanceToChecked(LatLon{Lat: math.NaN()}); return err }(), want: ErrOutOfRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.True(t, errors.Is(tt.err, tt.want), "got %v", tt.err)
+			for _, other := range []error{ErrInvalidGridRef, ErrInvalidDegrees, ErrUnknownDatum, ErrOutOfRange} {
+				if other != tt.want {
+					assert.False(t, errors.Is(tt.err, other), "got %v", tt.err)
+				}
+			}
+		})
+	}
+
+	d, err := DatumByName("OSGB36")
+	assert.NoError(t, err)
+	assert.Equal(t, OSGB36, d)
+}
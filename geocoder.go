@@ -0,0 +1,44 @@
+package osgridref
+
+import "sync"
+
+// Geocoder turns locations into place names (reverse geocoding). The package does not provide any
+// place name data; register an implementation, backed by a gazetteer or an online service, with
+// WithGeocoder.
+type Geocoder interface {
+	// Nearest returns the name of the place nearest to the point.
+	Nearest(point LatLon) (string, error)
+}
+
+// noGeocoder is the default Geocoder, which knows no place names.
+type noGeocoder struct{}
+
+func (noGeocoder) Nearest(LatLon) (string, error) { return "", nil }
+
+var (
+	geocoderMu sync.RWMutex
+	geocoder   Geocoder = noGeocoder{}
+)
+
+// WithGeocoder registers the Geocoder used by OsGridRef.PlaceName, replacing any registered
+// previously. Registering nil restores the default, which returns an empty name for every
+// location. It is safe to call concurrently with PlaceName.
+func WithGeocoder(g Geocoder) {
+	if g == nil {
+		g = noGeocoder{}
+	}
+	geocoderMu.Lock()
+	geocoder = g
+	geocoderMu.Unlock()
+}
+
+// PlaceName returns the name of the place nearest to the grid reference, as given by the Geocoder
+// registered with WithGeocoder for the reference's (WGS84) lat/lon. Without a registered Geocoder
+// the name is empty.
+func (o OsGridRef) PlaceName() (string, error) {
+	geocoderMu.RLock()
+	g := geocoder
+	geocoderMu.RUnlock()
+
+	return g.Nearest(o.ToLatLonStruct())
+}
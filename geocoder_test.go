@@ -0,0 +1,62 @@
+package osgridref
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGeocoder names the nearest of a fixed set of places, recording the points it is asked about.
+type fakeGeocoder struct {
+	places map[string]LatLon
+	asked  []LatLon
+}
+
+func (f *fakeGeocoder) Nearest(point LatLon) (string, error) {
+	f.asked = append(f.asked, point)
+	name, best := "", 0.0
+	for n, p := range f.places {
+		if d := point.DistanceTo(p); name == "" || d < best {
+			name, best = n, d
+		}
+	}
+	if name == "" {
+		return "", errors.New("no places")
+	}
+	return name, nil
+}
+
+func TestOsGridRef_PlaceName(t *testing.T) {
+	cambridgeRef := OsGridRef{Easting: 544982, Northing: 257869}
+
+	// no-op default
+	name, err := cambridgeRef.PlaceName()
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+
+	fake := &fakeGeocoder{places: map[string]LatLon{"Cambridge": cambridge, "Paris": paris, "Greenwich": greenwich}}
+	WithGeocoder(fake)
+	t.Cleanup(func() { WithGeocoder(nil) })
+
+	name, err = cambridgeRef.PlaceName()
+	require.NoError(t, err)
+	assert.Equal(t, "Cambridge", name)
+	assert.Equal(t, []LatLon{cambridgeRef.ToLatLonStruct()}, fake.asked)
+
+	name, err = OsGridRef{Easting: 538874, Northing: 177344}.PlaceName()
+	require.NoError(t, err)
+	assert.Equal(t, "Greenwich", name)
+
+	// errors are passed back to the caller
+	WithGeocoder(&fakeGeocoder{})
+	_, err = cambridgeRef.PlaceName()
+	assert.Error(t, err)
+
+	// nil restores the default
+	WithGeocoder(nil)
+	name, err = cambridgeRef.PlaceName()
+	require.NoError(t, err)
+	assert.Equal(t, "", name)
+}
@@ -0,0 +1,46 @@
+package osgridref
+
+// Geofence is an area of the earth's surface, for testing whether points lie within it.
+type Geofence interface {
+	// Contains reports whether the point lies within the fence.
+	Contains(point LatLon) bool
+}
+
+// CircleFence is a geofence containing all points within RadiusMetres of Centre.
+type CircleFence struct {
+	Centre       LatLon
+	RadiusMetres float64
+}
+
+// Contains reports whether the point is within RadiusMetres of the fence's centre.
+func (c CircleFence) Contains(point LatLon) bool {
+	return point.IsWithinDistance(c.Centre, c.RadiusMetres)
+}
+
+// PolygonFence is a geofence containing all points enclosed by a polygon, whose vertices are given
+// in order. The polygon need not be explicitly closed.
+type PolygonFence struct {
+	Vertices []LatLon
+}
+
+// Contains reports whether the point is enclosed by the fence's polygon. A polygon with fewer than
+// 3 vertices encloses nothing.
+func (p PolygonFence) Contains(point LatLon) bool {
+	if len(p.Vertices) < 3 {
+		return false
+	}
+	return point.IsEnclosedBy(p.Vertices)
+}
+
+// MultiFence is a geofence containing all points contained by any of its fences.
+type MultiFence []Geofence
+
+// Contains reports whether the point is contained by any of the fences.
+func (m MultiFence) Contains(point LatLon) bool {
+	for _, fence := range m {
+		if fence.Contains(point) {
+			return true
+		}
+	}
+	return false
+}
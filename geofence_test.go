@@ -0,0 +1,42 @@
+package osgridref
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeofence(t *testing.T) {
+	circle := CircleFence{Centre: cambridge, RadiusMetres: 50_000}
+	polygon := PolygonFence{Vertices: []LatLon{
+		{Lat: 48.5, Lon: 1.8},
+		{Lat: 48.5, Lon: 2.8},
+		{Lat: 49.2, Lon: 2.8},
+		{Lat: 49.2, Lon: 1.8},
+	}}
+	multi := MultiFence{circle, polygon}
+
+	tests := []struct {
+		name        string
+		point       LatLon
+		wantCircle  bool
+		wantPolygon bool
+	}{
+		{name: "cambridge", point: cambridge, wantCircle: true, wantPolygon: false},
+		{name: "stansted", point: stansted, wantCircle: true, wantPolygon: false},
+		{name: "paris", point: paris, wantCircle: false, wantPolygon: true},
+		{name: "cdg", point: cdg, wantCircle: false, wantPolygon: true},
+		{name: "greenwich", point: greenwich, wantCircle: false, wantPolygon: false},
+		{name: "brussels", point: bxl, wantCircle: false, wantPolygon: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantCircle, circle.Contains(tt.point))
+			assert.Equal(t, tt.wantPolygon, polygon.Contains(tt.point))
+			assert.Equal(t, tt.wantCircle || tt.wantPolygon, multi.Contains(tt.point))
+		})
+	}
+
+	assert.False(t, PolygonFence{}.Contains(paris))
+	assert.False(t, MultiFence{}.Contains(paris))
+}
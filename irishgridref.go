@@ -0,0 +1,53 @@
+package osgridref
+
+import "math"
+
+// irishGrid is the projection of Irl1975 latitude/longitude used by the Irish Grid, the national
+// grid of both Northern Ireland and the Republic of Ireland.
+var irishGrid = TransverseMercator{
+	Ellipsoid: ellipsoids["AiryModified"],
+	F0:        1.000035,
+	Lat0:      53.5,
+	Lon0:      -8,
+	E0:        200e3,
+	N0:        250e3,
+}
+
+// IrishGrid returns the projection of Irl1975 latitude/longitude used by the Irish Grid. As with
+// NationalGrid, it is a copy.
+func IrishGrid() TransverseMercator {
+	return irishGrid
+}
+
+// IrishGridRef represents an Irish Grid reference, in metres from the false origin of the Irish
+// Grid (south-west of Ireland).
+type IrishGridRef struct {
+	Easting, Northing int
+}
+
+// ToLatLon converts the Irish Grid reference to a lat/lon based on the WGS84 datum.
+func (i IrishGridRef) ToLatLon() (float64, float64) {
+	lat, lon := irishGrid.Inverse(float64(i.Easting), float64(i.Northing))
+	converted := LatLonEllipsoidalDatum{Lat: lat, Lon: lon, Datum: Datums["Irl1975"]}.ConvertDatum(WGS84)
+	return converted.Lat, converted.Lon
+}
+
+// ConvertGBtoIrish converts an OS National Grid reference to the Irish Grid reference of the same
+// point, for example for data straddling the Irish Sea. The grid reference is projected back to an
+// OSGB36 lat/lon, converted via WGS84 to Irl1975, then projected onto the Irish Grid. Each datum
+// conversion is a Helmert transformation, so the result is accurate only to several metres.
+//
+// The National Grid extends over the east of Ireland, so references there may be converted, but
+// no check is made that either reference is in a sensible area for its grid.
+func ConvertGBtoIrish(o OsGridRef) IrishGridRef {
+	lat, lon := nationalGrid.Inverse(float64(o.Easting), float64(o.Northing))
+
+	// ConvertDatum goes via WGS84 (all the datum transforms are relative to it)
+	irl := LatLonEllipsoidalDatum{Lat: lat, Lon: lon, Datum: OSGB36}.ConvertDatum(Datums["Irl1975"])
+
+	e, n := irishGrid.Forward(irl.Lat, irl.Lon)
+	return IrishGridRef{
+		Easting:  int(math.Round(e)),
+		Northing: int(math.Round(n)),
+	}
+}
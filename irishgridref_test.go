@@ -0,0 +1,42 @@
+package osgridref
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIrishGrid_Origin(t *testing.T) {
+	e, n := IrishGrid().Forward(53.5, -8)
+	assert.InDelta(t, 200000, e, 1e-6)
+	assert.InDelta(t, 250000, n, 1e-6)
+
+	lat, lon := IrishGrid().Inverse(200000, 250000)
+	assert.InDelta(t, 53.5, lat, 1e-9)
+	assert.InDelta(t, -8, lon, 1e-9)
+}
+
+func TestConvertGBtoIrish(t *testing.T) {
+	tests := []struct {
+		name string
+		gb   OsGridRef
+		want IrishGridRef
+	}{
+		// Belfast City Hall, Irish Grid J 3383 7408
+		{name: "Belfast", gb: OsGridRef{Easting: 146241, Northing: 529560}, want: IrishGridRef{Easting: 333828, Northing: 374088}},
+		// Strangford Lough, near the east coast of Northern Ireland
+		{name: "Strangford", gb: OsGridRef{Easting: 172365, Northing: 495029}, want: IrishGridRef{Easting: 362796, Northing: 341907}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ConvertGBtoIrish(tt.gb)
+			assert.InDelta(t, tt.want.Easting, got.Easting, 2)
+			assert.InDelta(t, tt.want.Northing, got.Northing, 2)
+
+			// both references should denote (nearly) the same WGS84 point
+			lat, lon := got.ToLatLon()
+			gbLat, gbLon := tt.gb.ToLatLon()
+			assert.Less(t, LatLon{Lat: lat, Lon: lon}.DistanceTo(LatLon{Lat: gbLat, Lon: gbLon}), 1.0)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package osgridref
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Binary encodings of a LatLon: the latitude followed by the longitude, each big-endian, as either
+// float32s (8 bytes in all) or float64s (16 bytes).
+const (
+	binaryLenCompact = 8
+	binaryLenFull    = 16
+)
+
+// MarshalBinary encodes the point in the compact 8-byte form, as two float32s. A float32 holds 24
+// significant bits, so a coordinate is stored to within about 4e-6° of latitude and 8e-6° of
+// longitude: better than a metre on the ground, which is adequate for most mapping but not for
+// survey work. Use AppendBinaryFormat with full set for an exact 16-byte encoding.
+func (ll LatLon) MarshalBinary() ([]byte, error) {
+	return ll.AppendBinaryFormat(make([]byte, 0, binaryLenCompact), false), nil
+}
+
+// AppendBinaryFormat appends the binary encoding of the point to b, returning the extended slice.
+// If full is false this is the compact 8-byte form written by MarshalBinary, otherwise the exact
+// 16-byte form, as two float64s. Appending to a single buffer avoids an allocation per point when
+// encoding many points.
+func (ll LatLon) AppendBinaryFormat(b []byte, full bool) []byte {
+	var buf [binaryLenFull]byte
+	if full {
+		binary.BigEndian.PutUint64(buf[0:], math.Float64bits(ll.Lat))
+		binary.BigEndian.PutUint64(buf[8:], math.Float64bits(ll.Lon))
+		return append(b, buf[:binaryLenFull]...)
+	}
+
+	binary.BigEndian.PutUint32(buf[0:], math.Float32bits(float32(ll.Lat)))
+	binary.BigEndian.PutUint32(buf[4:], math.Float32bits(float32(ll.Lon)))
+	return append(b, buf[:binaryLenCompact]...)
+}
+
+// UnmarshalBinary decodes a point encoded by MarshalBinary or AppendBinaryFormat, telling the
+// compact and full forms apart by their length.
+func (ll *LatLon) UnmarshalBinary(data []byte) error {
+	switch len(data) {
+	case binaryLenCompact:
+		ll.Lat = float64(math.Float32frombits(binary.BigEndian.Uint32(data[0:])))
+		ll.Lon = float64(math.Float32frombits(binary.BigEndian.Uint32(data[4:])))
+	case binaryLenFull:
+		ll.Lat = math.Float64frombits(binary.BigEndian.Uint64(data[0:]))
+		ll.Lon = math.Float64frombits(binary.BigEndian.Uint64(data[8:]))
+	default:
+		return fmt.Errorf("invalid binary LatLon: %d bytes, want %d or %d", len(data), binaryLenCompact, binaryLenFull)
+	}
+	return nil
+}
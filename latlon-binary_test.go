@@ -0,0 +1,68 @@
+package osgridref
+
+import (
+	"encoding"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = LatLon{}
+	_ encoding.BinaryUnmarshaler = &LatLon{}
+)
+
+func TestLatLon_MarshalBinary(t *testing.T) {
+	points := []LatLon{cambridge, paris, greenwich, {Lat: -90, Lon: -180}, {Lat: 90, Lon: 180}, {}}
+
+	for _, p := range points {
+		b, err := p.MarshalBinary()
+		require.NoError(t, err)
+		assert.Len(t, b, 8)
+
+		var got LatLon
+		require.NoError(t, got.UnmarshalBinary(b))
+		assert.InDelta(t, p.Lat, got.Lat, 4e-6, "%v", p)
+		assert.InDelta(t, p.Lon, got.Lon, 8e-6, "%v", p)
+
+		full := p.AppendBinaryFormat(nil, true)
+		assert.Len(t, full, 16)
+		require.NoError(t, got.UnmarshalBinary(full))
+		assert.Equal(t, p, got, "full encoding is exact")
+	}
+
+	// Appending many points to one buffer.
+	var buf []byte
+	for _, p := range points {
+		buf = p.AppendBinaryFormat(buf, true)
+	}
+	require.Len(t, buf, 16*len(points))
+	for i, p := range points {
+		var got LatLon
+		require.NoError(t, got.UnmarshalBinary(buf[i*16:(i+1)*16]))
+		assert.Equal(t, p, got)
+	}
+
+	var got LatLon
+	assert.Error(t, got.UnmarshalBinary(nil))
+	assert.Error(t, got.UnmarshalBinary(make([]byte, 12)))
+}
+
+func TestLatLon_MarshalBinary_PrecisionLoss(t *testing.T) {
+	// The compact encoding is within a metre anywhere on the earth.
+	r := rand.New(rand.NewSource(1))
+	maxErr := 0.0
+	for i := 0; i < 10000; i++ {
+		p := LatLon{Lat: r.Float64()*180 - 90, Lon: r.Float64()*360 - 180}
+		b, err := p.MarshalBinary()
+		require.NoError(t, err)
+		var got LatLon
+		require.NoError(t, got.UnmarshalBinary(b))
+		maxErr = math.Max(maxErr, p.DistanceTo(got))
+	}
+	assert.Less(t, maxErr, 1.0)
+	assert.Greater(t, maxErr, 0.0)
+}
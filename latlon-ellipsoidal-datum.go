@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 /* - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -  */
@@ -39,6 +41,21 @@ import (
 // Ellipsoid parameters.
 type Ellipseoid struct{ a, b, f float64 }
 
+// Ellipsoid is the correctly-spelled name for Ellipseoid.
+type Ellipsoid = Ellipseoid
+
+// A returns the semi-major axis of the ellipsoid, in metres.
+func (e Ellipseoid) A() float64 { return e.a }
+
+// B returns the semi-minor axis of the ellipsoid, in metres.
+func (e Ellipseoid) B() float64 { return e.b }
+
+// F returns the flattening of the ellipsoid.
+func (e Ellipseoid) F() float64 { return e.f }
+
+// EccentricitySquared returns the square of the first eccentricity of the ellipsoid, (a²−b²)/a².
+func (e Ellipseoid) EccentricitySquared() float64 { return 2*e.f - e.f*e.f }
+
 var (
 	ellipsoids = map[string]Ellipseoid{
 		"WGS84":         {a: 6378137, b: 6356752.314245, f: 1 / 298.257223563},
@@ -80,6 +97,40 @@ var Datums = map[string]Datum{
 	"WGS84":      {Name: "WGS84", Ellipsoid: ellipsoids["WGS84"], Transform: [7]float64{0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0}},
 }
 
+// DatumByName returns the datum from Datums with the given name, such as "OSGB36".
+func DatumByName(name string) (Datum, error) {
+	d, ok := Datums[name]
+	if !ok {
+		return Datum{}, fmt.Errorf("%w: %q", ErrUnknownDatum, name)
+	}
+	return d, nil
+}
+
+// TransformParams returns the labelled parameters of the Helmert transform from WGS-84 into the
+// datum: translations in metres, scale in ppm and rotations in arcseconds.
+func (d Datum) TransformParams() (tx, ty, tz, scale, rx, ry, rz float64) {
+	t := d.Transform
+	return t[0], t[1], t[2], t[3], t[4], t[5], t[6]
+}
+
+// String returns the datum name, ellipsoid and transform parameters, e.g.
+//   OSGB36 (Airy1830; tx=-446.448m ty=125.157m tz=-542.06m s=20.4894ppm rx=-0.1502″ ry=-0.247″ rz=-0.8421″)
+func (d Datum) String() string {
+	tx, ty, tz, scale, rx, ry, rz := d.TransformParams()
+	return fmt.Sprintf("%s (%s; tx=%gm ty=%gm tz=%gm s=%gppm rx=%g″ ry=%g″ rz=%g″)",
+		d.Name, ellipsoidName(d.Ellipsoid), tx, ty, tz, scale, rx, ry, rz)
+}
+
+// ellipsoidName returns the name of a known ellipsoid, or its axes if it is not one of ours.
+func ellipsoidName(e Ellipseoid) string {
+	for name, known := range ellipsoids {
+		if known == e {
+			return name
+		}
+	}
+	return fmt.Sprintf("a=%.3fm b=%.3fm", e.a, e.b)
+}
+
 var (
 	OSGB36 = Datums["OSGB36"]
 	WGS84  = Datums["WGS84"]
@@ -113,10 +164,26 @@ type LatLonEllipsoidalDatum struct {
 	Datum            Datum
 }
 
+// NewLatLonDatum returns the point with the given latitude and longitude (degrees) and height
+// (metres above the ellipsoid) on the given datum, or an error wrapping ErrOutOfRange if the
+// latitude is outside ±90° or the longitude outside ±180°. The zero Datum is taken to be WGS84.
+func NewLatLonDatum(lat, lon, height float64, datum Datum) (LatLonEllipsoidalDatum, error) {
+	if err := (LatLon{Lat: lat, Lon: lon}).check(); err != nil {
+		return LatLonEllipsoidalDatum{}, err
+	}
+	if datum == (Datum{}) {
+		datum = WGS84
+	}
+	return LatLonEllipsoidalDatum{Lat: lat, Lon: lon, Height: height, Datum: datum}, nil
+}
+
 // Parses a latitude/longitude point from a variety of formats.
 //
 // Latitude & longitude (in degrees) can be supplied as a single
-// comma-separated lat/lon string.
+// comma-separated lat/lon string. Without a comma, the latitude is taken to end at its N/S
+// compass direction (e.g. '51°28′40″N 000°00′05″W'), or failing that the string must be two
+// white-space separated numbers. Compass directions may also precede the values, as in
+// 'N 51.5 W 0.1'; a letter N or S within a word (e.g. 'DEGREES') is not taken as a direction.
 //
 // The latitude/longitude values may be numeric or strings; they may be signed decimal or
 // deg-min-sec (hexagesimal) suffixed by compass direction (NSEW); a variety of separators are
@@ -126,21 +193,20 @@ type LatLonEllipsoidalDatum struct {
 //   p1 = LatLon.parse('51.47736, 0.0000', 0, OSGB36);
 //   p2 = LatLon.parse('51°28′40″N, 000°00′05″W', 17, WGS84);
 func ParseLatLon(latLon string, height float64, datum Datum) (LatLonEllipsoidalDatum, error) {
-	errMessage := fmt.Errorf("invalid LatLon: '%s'", latLon)
+	errMessage := fmt.Errorf("%w in LatLon: '%s'", ErrInvalidDegrees, latLon)
 
 	if datum.Name == "" {
 		datum = WGS84
 	}
 
-	// single comma-separated lat/lon
-	parts := strings.Split(latLon, ",")
-	if len(parts) != 2 {
+	latStr, lonStr, ok := splitLatLon(latLon)
+	if !ok {
 		return LatLonEllipsoidalDatum{}, errMessage
 	}
 
-	lat, err1 := ParseDegrees(parts[0])
+	lat, err1 := ParseDegrees(latStr)
 	lat = Wrap90(lat)
-	lon, err2 := ParseDegrees(parts[1])
+	lon, err2 := ParseDegrees(lonStr)
 	lon = Wrap180(lon)
 
 	if err1 != nil || err2 != nil {
@@ -155,6 +221,94 @@ func ParseLatLon(latLon string, height float64, datum Datum) (LatLonEllipsoidalD
 	}, nil
 }
 
+// ParseLatLonOrder parses a pair of comma-separated coordinates, such as "51.5,-0.1", into a
+// LatLon. If lonFirst is set the longitude is taken to come first, as in GeoJSON ("-0.1,51.5").
+// Each coordinate may be in any form accepted by ParseDegrees. The latitude must be within ±90°
+// and the longitude within ±180°; unlike ParseLatLon, out of range values are an error rather than
+// being wrapped, since they most likely mean the order is wrong.
+func ParseLatLonOrder(s string, lonFirst bool) (LatLon, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return LatLon{}, fmt.Errorf("%w in LatLon: '%s'", ErrInvalidDegrees, s)
+	}
+	if lonFirst {
+		parts[0], parts[1] = parts[1], parts[0]
+	}
+
+	lat, err := ParseDegrees(parts[0])
+	if err != nil {
+		return LatLon{}, err
+	}
+	lon, err := ParseDegrees(parts[1])
+	if err != nil {
+		return LatLon{}, err
+	}
+
+	ll := LatLon{Lat: lat, Lon: lon}
+	if err := ll.check(); err != nil {
+		return LatLon{}, err
+	}
+	return ll, nil
+}
+
+// splitLatLon splits a combined lat/lon string into its latitude and longitude parts: at a single
+// comma if there is one, otherwise just after the latitude's N/S compass direction (or, if that
+// precedes the latitude, just before the longitude's E/W), otherwise at the white space between two
+// plain numbers. A compass direction given before a value is moved after it, as ParseDegrees
+// expects.
+func splitLatLon(latLon string) (lat, lon string, ok bool) {
+	if strings.Contains(latLon, ",") {
+		parts := strings.Split(latLon, ",")
+		if len(parts) != 2 {
+			return "", "", false
+		}
+		return suffixCompass(parts[0]), suffixCompass(parts[1]), true
+	}
+
+	if i := compassIndex(latLon, "NS"); i >= 0 {
+		if strings.TrimSpace(latLon[:i]) != "" {
+			return latLon[:i+1], latLon[i+1:], true
+		}
+		// prefixed, as in "N 51.5 W 0.1"
+		if j := compassIndex(latLon[i+1:], "EW"); j >= 0 {
+			j += i + 1
+			return suffixCompass(latLon[:j]), suffixCompass(latLon[j:]), true
+		}
+		return "", "", false
+	}
+
+	parts := strings.Fields(latLon)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// compassIndex returns the index in s of the first of the compass letters which stands alone,
+// rather than being part of a word such as "DEGREES", or -1 if there is none.
+func compassIndex(s, letters string) int {
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(letters, s[i]) < 0 {
+			continue
+		}
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[i+1:])
+		if !unicode.IsLetter(before) && !unicode.IsLetter(after) {
+			return i
+		}
+	}
+	return -1
+}
+
+// suffixCompass moves a compass direction preceding the value in s, as in "N 51.5", to follow it.
+func suffixCompass(s string) string {
+	s = strings.TrimSpace(s)
+	if compassIndex(s, "NSEW") == 0 {
+		return strings.TrimSpace(s[1:]) + " " + s[:1]
+	}
+	return s
+}
+
 // Converts ‘this’ lat/lon coordinate to new coordinate system.
 //
 // @param   toDatum - Datum this coordinate is to be converted to.
@@ -174,6 +328,28 @@ func (l LatLonEllipsoidalDatum) ToLatLon() LatLon {
 	return LatLon{Lat: l.Lat, Lon: l.Lon}
 }
 
+// Converts ‘this’ (spherical) lat/lon point from one datum to another, as
+// LatLonEllipsoidalDatum.ConvertDatum does, but without having to construct the ellipsoidal point.
+// The height is taken to be zero on the from datum; the converted height is discarded.
+//
+// example
+//   pWGS84 = LatLon{Lat: 51.47788, Lon: -0.00147};
+//   pOSGB = pWGS84.ConvertDatum(WGS84, OSGB36); // 51.4773°N, 000.0001°E
+func (ll LatLon) ConvertDatum(from, to Datum) LatLon {
+	return LatLonEllipsoidalDatum{Lat: ll.Lat, Lon: ll.Lon, Datum: from}.ConvertDatum(to).ToLatLon()
+}
+
+// ToCartesianWGS84 converts this WGS84 lat/lon, at the given height in metres above the
+// ellipsoid, to geocentric (ECEF) cartesian coordinates; it is shorthand for ToCartesian of the
+// equivalent LatLonEllipsoidalDatum.
+//
+// example
+//   p = LatLon{Lat: 0, Lon: 0};
+//   c = p.ToCartesianWGS84(0); // [6378137, 0, 0]
+func (ll LatLon) ToCartesianWGS84(height float64) Cartesian {
+	return LatLonEllipsoidalDatum{Lat: ll.Lat, Lon: ll.Lon, Height: height, Datum: WGS84}.ToCartesian()
+}
+
 // Converts ‘this’ point from (geodetic) latitude/longitude coordinates to (geocentric) cartesian
 // (x/y/z) coordinates, based on the same datum.
 //
@@ -209,6 +385,24 @@ func (l LatLonEllipsoidalDatum) ToCartesian() Cartesian {
 	}
 }
 
+// SlantDistanceTo returns the straight-line (3D) distance in metres between this point and
+// another, taking account of their heights; the other point is converted to this point's datum
+// first if necessary. Unlike a surface distance, this passes beneath the surface between distant
+// points.
+//
+// example
+//   p1 = LatLonEllipsoidalDatum{Lat: 52.205, Lon: 0.119, Height: 0, Datum: WGS84}
+//   p2 = LatLonEllipsoidalDatum{Lat: 52.205, Lon: 0.133628, Height: 500, Datum: WGS84}
+//   d = p1.SlantDistanceTo(p2) // 1118.0 (surface distance 1000m)
+func (l LatLonEllipsoidalDatum) SlantDistanceTo(point LatLonEllipsoidalDatum) float64 {
+	if point.Datum.Name != l.Datum.Name {
+		point = point.ConvertDatum(l.Datum)
+	}
+
+	c1, c2 := l.ToCartesian(), point.ToCartesian()
+	return Vector3d{X: c2.X - c1.X, Y: c2.Y - c1.Y, Z: c2.Z - c1.Z}.Length()
+}
+
 // ToOsGridRef returns the OS grid reference equivalent to this LatLon.
 func (l LatLonEllipsoidalDatum) ToOsGridRef() OsGridRef {
 	// if necessary convert to OSGB36 first
@@ -217,43 +411,7 @@ func (l LatLonEllipsoidalDatum) ToOsGridRef() OsGridRef {
 		point = point.ConvertDatum(OSGB36)
 	}
 
-	φ := point.Lat * toRadians
-	λ := point.Lon * toRadians
-
-	cosφ := math.Cos(φ)
-	sinφ := math.Sin(φ)
-	ν := a * F0 / math.Sqrt(1-e2*sinφ*sinφ)                // nu = transverse radius of curvature
-	ρ := a * F0 * (1 - e2) / math.Pow(1-e2*sinφ*sinφ, 1.5) // rho = meridional radius of curvature
-	η2 := ν/ρ - 1                                          // eta = ?
-
-	Ma := (1 + n + (5/4)*n2 + (5/4)*n3) * (φ - φ0)
-	Mb := (3*n + 3*n*n + (21/8)*n3) * math.Sin(φ-φ0) * math.Cos(φ+φ0)
-	Mc := ((15/8)*n2 + (15/8)*n3) * math.Sin(2*(φ-φ0)) * math.Cos(2*(φ+φ0))
-	Md := (35 / 24) * n3 * math.Sin(3*(φ-φ0)) * math.Cos(3*(φ+φ0))
-	M := b * F0 * (Ma - Mb + Mc - Md) // meridional arc
-
-	cos3φ := cosφ * cosφ * cosφ
-	cos5φ := cos3φ * cosφ * cosφ
-	tan2φ := math.Tan(φ) * math.Tan(φ)
-	tan4φ := tan2φ * tan2φ
-
-	I := M + N0
-	II := (ν / 2) * sinφ * cosφ
-	III := (ν / 24) * sinφ * cos3φ * (5 - tan2φ + 9*η2)
-	IIIA := (ν / 720) * sinφ * cos5φ * (61 - 58*tan2φ + tan4φ)
-	IV := ν * cosφ
-	V := (ν / 6) * cos3φ * (ν/ρ - tan2φ)
-	VI := (ν / 120) * cos5φ * (5 - 18*tan2φ + tan4φ + 14*η2 - 58*tan2φ*η2)
-
-	Δλ := λ - λ0
-	Δλ2 := Δλ * Δλ
-	Δλ3 := Δλ2 * Δλ
-	Δλ4 := Δλ3 * Δλ
-	Δλ5 := Δλ4 * Δλ
-	Δλ6 := Δλ5 * Δλ
-
-	N := I + II*Δλ2 + III*Δλ4 + IIIA*Δλ6
-	E := E0 + IV*Δλ + V*Δλ3 + VI*Δλ5
+	E, N := nationalGrid.Forward(point.Lat, point.Lon)
 
 	return OsGridRef{
 		Easting:  int(math.Round(E)),
@@ -261,6 +419,18 @@ func (l LatLonEllipsoidalDatum) ToOsGridRef() OsGridRef {
 	}
 }
 
+// ToOsGridRefChecked is equivalent to ToOsGridRef, but returns an error wrapping ErrOutOfRange if the
+// point lies outside the National Grid (see OsGridRef.Valid), such as in Paris or the mid-Atlantic.
+// Note that the grid is a rectangle, so some points off the coast, or in northern France, are
+// within it.
+func (l LatLonEllipsoidalDatum) ToOsGridRefChecked() (OsGridRef, error) {
+	o := l.ToOsGridRef()
+	if !o.Valid() {
+		return OsGridRef{}, fmt.Errorf("%w: %v is outside the OS grid", ErrOutOfRange, l.ToLatLon())
+	}
+	return o, nil
+}
+
 /* Cartesian  - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - */
 
 // Cartesian coordinate representing ECEF (earth-centric earth-fixed) point, on a given
@@ -358,6 +528,16 @@ func (c Cartesian) ConvertDatum(toDatum Datum) Cartesian {
 	return newCartesian
 }
 
+// ToOsGridRef returns the OS grid reference equivalent to this cartesian point, converting it to
+// the OSGB36 datum first if necessary.
+//
+// example
+//   c = Cartesian{X: 3917393.532, Y: 8203.853, Z: 5016472.149, Datum: WGS84}
+//   c.ToOsGridRef() // TL 44982 57869
+func (c Cartesian) ToOsGridRef() OsGridRef {
+	return c.ConvertDatum(OSGB36).ToLatLon().ToOsGridRef()
+}
+
 /**
  * Applies Helmert 7-parameter transformation to ‘this’ coordinate using transform parameters t.
  *
@@ -0,0 +1,244 @@
+package osgridref
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCartesian_ToOsGridRef(t *testing.T) {
+	tests := []struct {
+		name      string
+		cartesian Cartesian
+		want      OsGridRef
+	}{
+		{
+			// OSGB36 53.073851°N, 002.113526°W
+			name:      "OSGB36 SJ 92395 52997",
+			cartesian: Cartesian{X: 3837125.992, Y: -141608.091, Z: 5075111.906, Datum: OSGB36},
+			want:      OsGridRef{Easting: 392395, Northing: 352997},
+		},
+		{
+			name:      "WGS84 TL 44982 57869",
+			cartesian: Cartesian{X: 3917393.532, Y: 8203.853, Z: 5016472.149, Datum: WGS84},
+			want:      OsGridRef{Easting: 544982, Northing: 257869},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cartesian.ToOsGridRef()
+			// allow for the few metres' accuracy of the projection
+			assert.InDelta(t, tt.want.Easting, got.Easting, 3)
+			assert.InDelta(t, tt.want.Northing, got.Northing, 3)
+			assert.Equal(t, tt.cartesian.ToLatLon().ToOsGridRef(), got)
+		})
+	}
+}
+
+func TestEllipsoid_Accessors(t *testing.T) {
+	airy := OSGB36.Ellipsoid
+	assert.Equal(t, 6377563.396, airy.A())
+	assert.Equal(t, 6356256.909, airy.B())
+	assert.Equal(t, 1/299.3249646, airy.F())
+	assert.InDelta(t, e2, airy.EccentricitySquared(), 1e-9)
+
+	var wgs84 Ellipsoid = WGS84.Ellipsoid
+	assert.Equal(t, 6378137.0, wgs84.A())
+	assert.InDelta(t, 0.00669438, wgs84.EccentricitySquared(), 1e-8)
+}
+
+func TestDatum_TransformParams(t *testing.T) {
+	tx, ty, tz, scale, rx, ry, rz := OSGB36.TransformParams()
+	assert.Equal(t, [7]float64{-446.448, 125.157, -542.060, 20.4894, -0.1502, -0.2470, -0.8421}, [7]float64{tx, ty, tz, scale, rx, ry, rz})
+}
+
+func TestDatum_String(t *testing.T) {
+	assert.Equal(t, "OSGB36 (Airy1830; tx=-446.448m ty=125.157m tz=-542.06m s=20.4894ppm rx=-0.1502″ ry=-0.247″ rz=-0.8421″)", OSGB36.String())
+	assert.Equal(t, "WGS84 (WGS84; tx=0m ty=0m tz=0m s=0ppm rx=0″ ry=0″ rz=0″)", WGS84.String())
+
+	custom := Datum{Name: "Custom", Ellipsoid: Ellipsoid{a: 6378000, b: 6357000, f: 1 / 300.0}}
+	assert.Equal(t, "Custom (a=6378000.000m b=6357000.000m; tx=0m ty=0m tz=0m s=0ppm rx=0″ ry=0″ rz=0″)", custom.String())
+}
+
+func TestNewLatLonDatum(t *testing.T) {
+	tests := []struct {
+		name     string
+		lat, lon float64
+		datum    Datum
+		want     LatLonEllipsoidalDatum
+		wantErr  bool
+	}{
+		{name: "OSGB36", lat: 52.2, lon: 0.12, datum: OSGB36, want: LatLonEllipsoidalDatum{Lat: 52.2, Lon: 0.12, Height: 10, Datum: OSGB36}},
+		{name: "default datum", lat: -33.9, lon: 151.2, want: LatLonEllipsoidalDatum{Lat: -33.9, Lon: 151.2, Height: 10, Datum: WGS84}},
+		{name: "limits", lat: -90, lon: 180, datum: Datums["ED50"], want: LatLonEllipsoidalDatum{Lat: -90, Lon: 180, Height: 10, Datum: Datums["ED50"]}},
+		{name: "latitude out of range", lat: 90.5, lon: 0, datum: WGS84, wantErr: true},
+		{name: "longitude out of range", lat: 0, lon: -181, datum: WGS84, wantErr: true},
+		{name: "NaN", lat: math.NaN(), lon: 0, datum: WGS84, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewLatLonDatum(tt.lat, tt.lon, 10, tt.datum)
+			if tt.wantErr {
+				assert.True(t, errors.Is(err, ErrOutOfRange), "got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseLatLon(t *testing.T) {
+	tests := []struct {
+		name     string
+		latLon   string
+		lat, lon float64
+		wantErr  bool
+	}{
+		{name: "numeric", latLon: "51.47736, 0.0000", lat: 51.47736, lon: 0},
+		{name: "dms with comma", latLon: "51°28′40″N, 000°00′05″W", lat: 51.477778, lon: -0.001389},
+		{name: "dms without comma", latLon: "51°28′40″N 000°00′05″W", lat: 51.477778, lon: -0.001389},
+		{name: "spaced dms with comma", latLon: "51 28 40 N, 0 0 5 W", lat: 51.477778, lon: -0.001389},
+		{name: "spaced dms without comma", latLon: "51 28 40 N 0 0 5 W", lat: 51.477778, lon: -0.001389},
+		{name: "southern without comma", latLon: "33°52′S 151°12′E", lat: -33.866667, lon: 151.2},
+		{name: "numeric without comma", latLon: "51.47736 -0.5", lat: 51.47736, lon: -0.5},
+		{name: "upper-case degrees", latLon: "51.5 DEGREES N 0.1 DEGREES W", lat: 51.5, lon: -0.1},
+		{name: "lower-case degrees", latLon: "51.5 degrees N 0.1 degrees W", lat: 51.5, lon: -0.1},
+		{name: "southern degrees", latLon: "33.9 DEGREES S 151.2 DEGREES E", lat: -33.9, lon: 151.2},
+		{name: "prefixed compass", latLon: "N 51.5 W 0.1", lat: 51.5, lon: -0.1},
+		{name: "prefixed compass dms", latLon: "S 33°52′ E 151°12′", lat: -33.866667, lon: 151.2},
+		{name: "prefixed compass with comma", latLon: "N 51.5, W 0.1", lat: 51.5, lon: -0.1},
+		{name: "prefixed compass without longitude direction", latLon: "N 51.5 0.1", wantErr: true},
+		{name: "too many commas", latLon: "51, 28, 40", wantErr: true},
+		{name: "no separator", latLon: "51.47736", wantErr: true},
+		{name: "too many numbers", latLon: "51 28 40 0 0 5", wantErr: true},
+		{name: "no longitude", latLon: "51°28′40″N", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLatLon(tt.latLon, 0, WGS84)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.lat, got.Lat, 1e-6)
+			assert.InDelta(t, tt.lon, got.Lon, 1e-6)
+			assert.Equal(t, WGS84, got.Datum)
+		})
+	}
+}
+
+func TestLatLonEllipsoidalDatum_ToOsGridRefChecked(t *testing.T) {
+	newlyn := LatLonEllipsoidalDatum{Lat: 50.102909, Lon: -5.542765, Datum: WGS84}
+	o, err := newlyn.ToOsGridRefChecked()
+	require.NoError(t, err)
+	assert.Equal(t, newlyn.ToOsGridRef(), o)
+	assert.Equal(t, "SW 46760 28548", o.StringN(10))
+
+	for _, p := range []LatLonEllipsoidalDatum{
+		{Lat: 48.857, Lon: 2.351, Datum: WGS84}, // Paris
+		{Lat: 45, Lon: -30, Datum: WGS84},       // mid-Atlantic
+		{Lat: 62, Lon: -7, Datum: WGS84},        // Faroe Islands, north of the grid
+		{Lat: 51.5, Lon: 4.5, Datum: OSGB36},    // Netherlands, east of the grid
+	} {
+		_, err := p.ToOsGridRefChecked()
+		assert.True(t, errors.Is(err, ErrOutOfRange), "%v: got %v", p.ToLatLon(), err)
+	}
+}
+
+func TestParseLatLonOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		lonFirst bool
+		want     LatLon
+		wantErr  error
+	}{
+		{name: "lat first", s: "51.5,-0.1", want: LatLon{Lat: 51.5, Lon: -0.1}},
+		{name: "lon first", s: "-0.1,51.5", lonFirst: true, want: LatLon{Lat: 51.5, Lon: -0.1}},
+		{name: "lon first with spaces", s: " 151.2 , -33.9 ", lonFirst: true, want: LatLon{Lat: -33.9, Lon: 151.2}},
+		{name: "dms", s: "000°00′05″W, 51°28′40″N", lonFirst: true, want: LatLon{Lat: 51.477778, Lon: -0.001389}},
+		{name: "latitude out of range", s: "151.2,-33.9", wantErr: ErrOutOfRange},
+		{name: "longitude out of range", s: "51.5,181", wantErr: ErrOutOfRange},
+		{name: "invalid", s: "51.5,east", wantErr: ErrInvalidDegrees},
+		{name: "one value", s: "51.5", wantErr: ErrInvalidDegrees},
+		{name: "three values", s: "51.5,-0.1,12", lonFirst: true, wantErr: ErrInvalidDegrees},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLatLonOrder(tt.s, tt.lonFirst)
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want.Lat, got.Lat, 1e-6)
+			assert.InDelta(t, tt.want.Lon, got.Lon, 1e-6)
+		})
+	}
+}
+
+func TestLatLonEllipsoidalDatum_SlantDistanceTo(t *testing.T) {
+	p1 := LatLonEllipsoidalDatum{Lat: 52.205, Lon: 0.119, Height: 0, Datum: WGS84}
+	p2 := LatLonEllipsoidalDatum{Lat: 52.205, Lon: 0.133628, Height: 500, Datum: WGS84}
+	p2AtSurface := LatLonEllipsoidalDatum{Lat: p2.Lat, Lon: p2.Lon, Height: 0, Datum: WGS84}
+
+	surface := p1.SlantDistanceTo(p2AtSurface)
+	assert.InDelta(t, 1000, surface, 0.1)
+
+	slant := p1.SlantDistanceTo(p2)
+	assert.InDelta(t, math.Sqrt(1000*1000+500*500), slant, 0.5)
+	assert.Greater(t, slant, surface)
+	assert.Equal(t, slant, p2.SlantDistanceTo(p1))
+	assert.Equal(t, 0.0, p1.SlantDistanceTo(p1))
+
+	// the other point is converted to this point's datum
+	assert.InDelta(t, slant, p1.SlantDistanceTo(p2.ConvertDatum(OSGB36)), 0.01)
+}
+
+func TestLatLon_ConvertDatum(t *testing.T) {
+	greenwich := LatLon{Lat: 51.47788, Lon: -0.00147}
+
+	osgb := greenwich.ConvertDatum(WGS84, OSGB36)
+	assert.InDelta(t, 51.4773642, osgb.Lat, 1e-7)
+	assert.InDelta(t, 0.0001496, osgb.Lon, 1e-7)
+
+	want := LatLonEllipsoidalDatum{Lat: greenwich.Lat, Lon: greenwich.Lon, Datum: WGS84}.ConvertDatum(OSGB36)
+	assert.Equal(t, want.ToLatLon(), osgb)
+
+	// the transforms are not exact inverses, but agree to within a centimetre
+	back := osgb.ConvertDatum(OSGB36, WGS84)
+	assert.Less(t, greenwich.DistanceTo(back), 0.01)
+}
+
+func TestLatLon_ToCartesianWGS84(t *testing.T) {
+	a := WGS84.Ellipsoid.A()
+
+	tests := []struct {
+		name    string
+		point   LatLon
+		height  float64
+		x, y, z float64
+	}{
+		{name: "equator, prime meridian", point: LatLon{Lat: 0, Lon: 0}, x: a},
+		{name: "with height", point: LatLon{Lat: 0, Lon: 0}, height: 100, x: a + 100},
+		{name: "equator, 90°E", point: LatLon{Lat: 0, Lon: 90}, y: a},
+		{name: "north pole", point: LatLon{Lat: 90, Lon: 0}, z: WGS84.Ellipsoid.B()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.point.ToCartesianWGS84(tt.height)
+			assert.InDelta(t, tt.x, c.X, 1e-6)
+			assert.InDelta(t, tt.y, c.Y, 1e-6)
+			assert.InDelta(t, tt.z, c.Z, 1e-6)
+			assert.Equal(t, WGS84, c.Datum)
+		})
+	}
+
+	p := LatLonEllipsoidalDatum{Lat: cambridge.Lat, Lon: cambridge.Lon, Height: 20, Datum: WGS84}
+	assert.Equal(t, p.ToCartesian(), cambridge.ToCartesianWGS84(20))
+}
@@ -0,0 +1,130 @@
+package osgridref
+
+import (
+	"errors"
+	"math"
+)
+
+/* - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -  */
+/* Vincenty Direct and Inverse Solution of Geodesics on the Ellipsoid (c) Chris Veness 2002-2019  */
+/*                                                                                   MIT Licence  */
+/* www.movable-type.co.uk/scripts/latlong-ellipsoidal-vincenty.html                               */
+/* www.movable-type.co.uk/scripts/geodesy-library.html#latlon-ellipsoidal-vincenty                */
+/* - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -  */
+
+// Distances & bearings between points, and destination points given start points & initial
+// bearings, calculated on an ellipsoidal earth model using ‘direct and inverse solutions of
+// geodesics on the ellipsoid’ devised by Thaddeus Vincenty.
+//
+// From: T Vincenty, "Direct and Inverse Solutions of Geodesics on the Ellipsoid with application
+// of nested equations", Survey Review, vol XXIII no 176, 1975. www.ngs.noaa.gov/PUBS_LIB/inverse.pdf.
+
+var errVincentyConvergence = errors.New("Vincenty formula failed to converge")
+
+// DestinationPoint returns the destination point having travelled the given distance along a
+// geodesic given by initial bearing from this point, using Vincenty direct solution on the
+// point's datum ellipsoid. This is accurate to within 0.5mm on the ellipsoid, but is typically
+// an order of magnitude slower than the spherical LatLon.DestinationPoint.
+//
+// The point's height is carried over to the result, but is not otherwise used: the geodesic is
+// on the surface of the ellipsoid.
+//
+// example
+//   p1 = new LatLon(-37.95103, 144.42487);
+//   p2 = p1.destinationPoint(54972.271, 306.86816); // 37.6528°S, 143.9265°E
+func (l LatLonEllipsoidalDatum) DestinationPoint(distance, initialBearing float64) (LatLonEllipsoidalDatum, error) {
+	p, _, err := l.direct(distance, initialBearing)
+	return p, err
+}
+
+// FinalBearingOn returns the final bearing having travelled along a geodesic given by initial
+// bearing for a given distance from this point, using Vincenty direct solution.
+//
+// example
+//   p1 = new LatLon(-37.95103, 144.42487);
+//   b2 = p1.finalBearingOn(54972.271, 306.86816); // 307.1736°
+func (l LatLonEllipsoidalDatum) FinalBearingOn(distance, initialBearing float64) (float64, error) {
+	_, finalBearing, err := l.direct(distance, initialBearing)
+	return finalBearing, err
+}
+
+// direct is the Vincenty direct calculation: the destination point and final bearing (degrees)
+// after travelling distance (metres) from this point on the given initial bearing (degrees).
+func (l LatLonEllipsoidalDatum) direct(distance, initialBearing float64) (LatLonEllipsoidalDatum, float64, error) {
+	φ1, λ1 := l.Lat*toRadians, l.Lon*toRadians
+	α1 := initialBearing * toRadians
+	s := distance
+
+	a, b, f := l.Datum.Ellipsoid.a, l.Datum.Ellipsoid.b, l.Datum.Ellipsoid.f
+
+	sinα1 := math.Sin(α1)
+	cosα1 := math.Cos(α1)
+
+	tanU1 := (1 - f) * math.Tan(φ1)
+	cosU1 := 1 / math.Sqrt(1+tanU1*tanU1)
+	sinU1 := tanU1 * cosU1
+	σ1 := math.Atan2(tanU1, cosα1) // σ1 = angular distance on the sphere from the equator to P1
+	sinα := cosU1 * sinα1          // α = azimuth of the geodesic at the equator
+	cosSqα := 1 - sinα*sinα
+	uSq := cosSqα * (a*a - b*b) / (b * b)
+	A := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	B := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+
+	σ := s / (b * A)               // σ = angular distance P₁ P₂ on the sphere
+	var sinσ, cosσ, cos2σₘ float64 // σₘ = angular distance on the sphere from the equator to the midpoint of the line
+
+	iterations := 0
+	for {
+		cos2σₘ = math.Cos(2*σ1 + σ)
+		sinσ = math.Sin(σ)
+		cosσ = math.Cos(σ)
+		Δσ := B * sinσ * (cos2σₘ + B/4*(cosσ*(-1+2*cos2σₘ*cos2σₘ)-B/6*cos2σₘ*(-3+4*sinσ*sinσ)*(-3+4*cos2σₘ*cos2σₘ)))
+		σʹ := σ
+		σ = s/(b*A) + Δσ
+		iterations++
+		if math.Abs(σ-σʹ) <= 1e-12 {
+			break
+		}
+		if iterations >= 100 {
+			return LatLonEllipsoidalDatum{}, 0, errVincentyConvergence // not possible?
+		}
+	}
+
+	x := sinU1*sinσ - cosU1*cosσ*cosα1
+	φ2 := math.Atan2(sinU1*cosσ+cosU1*sinσ*cosα1, (1-f)*math.Sqrt(sinα*sinα+x*x))
+	λ := math.Atan2(sinσ*sinα1, cosU1*cosσ-sinU1*sinσ*cosα1)
+	C := f / 16 * cosSqα * (4 + f*(4-3*cosSqα))
+	L := λ - (1-C)*f*sinα*(σ+C*sinσ*(cos2σₘ+C*cosσ*(-1+2*cos2σₘ*cos2σₘ)))
+	λ2 := λ1 + L
+
+	α2 := math.Atan2(sinα, -x)
+
+	p := LatLonEllipsoidalDatum{Lat: φ2 * toDegrees, Lon: Wrap180(λ2 * toDegrees), Height: l.Height, Datum: l.Datum}
+	return p, Wrap360(α2 * toDegrees), nil
+}
+
+// Travel returns the destination point having travelled the given distance (metres) from this
+// (WGS84) point on the given initial bearing (degrees), either on a sphere or on the WGS84
+// ellipsoid.
+//
+// With ellipsoidal false this is LatLon.DestinationPoint: a simple closed-form calculation, but
+// the spherical model can be in error by up to around 0.5% of the distance travelled. With
+// ellipsoidal true it is LatLonEllipsoidalDatum.DestinationPoint: Vincenty's iterative solution,
+// accurate to within 0.5mm but typically an order of magnitude slower.
+//
+// An error is returned if this point is out of range, or (in principle) if the ellipsoidal
+// calculation fails to converge.
+func (ll LatLon) Travel(distance, bearing float64, ellipsoidal bool) (LatLon, error) {
+	if err := ll.check(); err != nil {
+		return LatLon{}, err
+	}
+	if !ellipsoidal {
+		return ll.DestinationPoint(distance, bearing), nil
+	}
+
+	p, err := LatLonEllipsoidalDatum{Lat: ll.Lat, Lon: ll.Lon, Datum: WGS84}.DestinationPoint(distance, bearing)
+	if err != nil {
+		return LatLon{}, err
+	}
+	return p.ToLatLon(), nil
+}
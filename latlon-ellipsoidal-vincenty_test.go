@@ -0,0 +1,64 @@
+package osgridref
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatLonEllipsoidalDatum_DestinationPoint(t *testing.T) {
+	// Flinders Peak to Buninyong, from Vincenty's paper
+	flindersPeak := LatLonEllipsoidalDatum{Lat: -37.95103, Lon: 144.42487, Height: 10, Datum: WGS84}
+
+	p, err := flindersPeak.DestinationPoint(54972.271, 306.86816)
+	require.NoError(t, err)
+	assert.InDelta(t, -37.6528177, p.Lat, 1e-7)
+	assert.InDelta(t, 143.9264977, p.Lon, 1e-7)
+	assert.Equal(t, 10.0, p.Height)
+	assert.Equal(t, WGS84, p.Datum)
+
+	finalBearing, err := flindersPeak.FinalBearingOn(54972.271, 306.86816)
+	require.NoError(t, err)
+	assert.InDelta(t, 307.1736, finalBearing, 1e-4)
+
+	// longitude is wrapped across the anti-meridian
+	p, err = LatLonEllipsoidalDatum{Lat: 0, Lon: 179, Datum: WGS84}.DestinationPoint(1000e3, 90)
+	require.NoError(t, err)
+	assert.InDelta(t, -172.016847, p.Lon, 1e-6)
+}
+
+func TestLatLon_Travel(t *testing.T) {
+	tests := []struct {
+		name    string
+		start   LatLon
+		bearing float64
+		// distance between the spherical and ellipsoidal destinations after 1000km
+		wantDiff float64
+	}{
+		{name: "north", start: cambridge, bearing: 0, wantDiff: 1424.1},
+		{name: "north-east", start: cambridge, bearing: 45, wantDiff: 2671.8},
+		{name: "equator", start: LatLon{Lat: 0, Lon: 0}, bearing: 90, wantDiff: 1119.0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spherical, err := tt.start.Travel(1000e3, tt.bearing, false)
+			require.NoError(t, err)
+			assert.Equal(t, tt.start.DestinationPoint(1000e3, tt.bearing), spherical)
+
+			ellipsoidal, err := tt.start.Travel(1000e3, tt.bearing, true)
+			require.NoError(t, err)
+			want, _ := LatLonEllipsoidalDatum{Lat: tt.start.Lat, Lon: tt.start.Lon, Datum: WGS84}.DestinationPoint(1000e3, tt.bearing)
+			assert.Equal(t, want.ToLatLon(), ellipsoidal)
+
+			// the models differ by a small fraction (well under 0.5%) of the distance travelled
+			diff := spherical.DistanceTo(ellipsoidal)
+			assert.InDelta(t, tt.wantDiff, diff, 0.1)
+			assert.Less(t, diff, 0.005*1000e3)
+		})
+	}
+
+	_, err := LatLon{Lat: 91, Lon: 0}.Travel(1000, 0, true)
+	assert.True(t, errors.Is(err, ErrOutOfRange), "got %v", err)
+}
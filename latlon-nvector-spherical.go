@@ -1,6 +1,9 @@
 package osgridref
 
-import "math"
+import (
+	"fmt"
+	"math"
+)
 
 /* - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -  */
 /* Vector-based spherical geodetic (latitude/longitude) functions     (c) Chris Veness 2011-2019  */
@@ -531,82 +534,115 @@ func (ll LatLon) toNVector() NvectorSpherical { // note: replicated in LatLon_Nv
 //}
 //
 //
-///**
-// * Returns closest point on great circle segment between point1 & point2 to ‘this’ point.
-// *
-// * If this point is ‘within’ the extent of the segment, the point is on the segment between point1 &
-// * point2; otherwise, it is the closer of the endpoints defining the segment.
-// *
-// * @param   {LatLon} point1 - Start point of great circle segment.
-// * @param   {LatLon} point2 - End point of great circle segment.
-// * @returns {LatLon} point on segment.
-// *
-// * @example
-// *   const p1 = new LatLon(51.0, 1.0);
-// *   const p2 = new LatLon(51.0, 2.0);
-// *
-// *   const p0 = new LatLon(51.0, 1.9);
-// *   const p = p0.nearestPointOnSegment(p1, p2); // 51.0004°N, 001.9000°E
-// *   const d = p.distanceTo(p);                  // 42.71 m
-// *
-// *   const p0 = new LatLon(51.0, 2.1);
-// *   const p = p0.nearestPointOnSegment(p1, p2); // 51.0000°N, 002.0000°E
-// */
-//nearestPointOnSegment(point1, point2) {
-//let p = null;
-//
-//if (this.isWithinExtent(point1, point2) && !point1.equals(point2)) {
-//// closer to segment than to its endpoints, find closest point on segment
-//const n0 = this.toNvector(), n1 = point1.toNvector(), n2 = point2.toNvector();
-//const c1 = n1.cross(n2); // n1×n2 = vector representing great circle through p1, p2
-//const c2 = n0.cross(c1); // n0×c1 = vector representing great circle through p0 normal to c1
-//const n = c1.cross(c2);  // c2×c1 = nearest point on c1 to n0
-//p = new NvectorSpherical(n.x, n.y, n.z).toLatLon();
-//} else {
-//// beyond segment extent, take closer endpoint
-//const d1 = this.distanceTo(point1);
-//const d2 = this.distanceTo(point2);
-//const pCloser = d1<d2 ? point1 : point2;
-//p = new LatLonNvectorSpherical(pCloser.lat, pCloser.lon);
-//}
-//
-//return p;
-//}
-//
-//
-///**
-// * Returns whether this point is within the extent of a line segment joining point 1 & point 2.
-// *
-// * If this point is not on the great circle defined by point1 & point 2, returns whether it is
-// * within the area bound by perpendiculars to the great circle at each point (in the same
-// * hemisphere).
-// *
-// * @param   {LatLon}  point1 - First point defining segment.
-// * @param   {LatLon}  point2 - Second point defining segment.
-// * @returns {boolean} Whether this point is within extent of segment.
-// *
-// * @example
-// *   const p1 = new LatLon(51, 1), p2 = new LatLon(52, 2);
-// *   const within1 = new LatLon(52, 1).isWithinExtent(p1, p2); // true
-// *   const within2 = new LatLon(51, 0).isWithinExtent(p1, p2); // false
-// */
-//isWithinExtent(point1, point2) {
-//if (point1.equals(point2)) return this.equals(point1); // null segment
-//
-//const n0 = this.toNvector(), n1 = point1.toNvector(), n2 = point2.toNvector(); // n-vectors
-//
-//// get vectors representing p0->p1, p0->p2, p1->p2, p2->p1
-//const δ10 = n0.minus(n1), δ12 = n2.minus(n1);
-//const δ20 = n0.minus(n2), δ21 = n1.minus(n2);
-//
-//// dot product δ10⋅δ12 tells us if p0 is on p2 side of p1, similarly for δ20⋅δ21
-//const extent1 = δ10.dot(δ12);
-//const extent2 = δ20.dot(δ21);
-//
-//const isSameHemisphere = n0.dot(n1)>=0 && n0.dot(n2)>=0;
-//
-//return extent1>=0 && extent2>=0 && isSameHemisphere;
-//}
+/**
+ * Returns closest point on great circle segment between point1 & point2 to ‘this’ point.
+ *
+ * If this point is ‘within’ the extent of the segment, the point is on the segment between point1 &
+ * point2; otherwise, it is the closer of the endpoints defining the segment.
+ *
+ * @param   {LatLon} point1 - Start point of great circle segment.
+ * @param   {LatLon} point2 - End point of great circle segment.
+ * @returns {LatLon} point on segment.
+ *
+ * @example
+ *   const p1 = new LatLon(51.0, 1.0);
+ *   const p2 = new LatLon(51.0, 2.0);
+ *
+ *   const p0 = new LatLon(51.0, 1.9);
+ *   const p = p0.NearestPointOnSegment(p1, p2); // 51.0004°N, 001.9000°E
+ *   const d = p.distanceTo(p);                  // 42.71 m
+ *
+ *   const p0 = new LatLon(51.0, 2.1);
+ *   const p = p0.NearestPointOnSegment(p1, p2); // 51.0000°N, 002.0000°E
+ */
+func (ll LatLon) NearestPointOnSegment(point1, point2 LatLon) LatLon {
+	if ll.IsWithinExtent(point1, point2) && !point1.Equals(point2) {
+		// closer to segment than to its endpoints, find closest point on segment
+		n0, n1, n2 := Vector3d(ll.toNVector()), Vector3d(point1.toNVector()), Vector3d(point2.toNVector())
+		c1 := n1.Cross(n2) // n1×n2 = vector representing great circle through p1, p2
+		c2 := n0.Cross(c1) // n0×c1 = vector representing great circle through p0 normal to c1
+		n := c1.Cross(c2)  // c2×c1 = nearest point on c1 to n0
+		return NvectorSpherical(n).toLatLon()
+	}
+
+	// beyond segment extent, take closer endpoint
+	if ll.DistanceTo(point1) < ll.DistanceTo(point2) {
+		return point1
+	}
+	return point2
+}
+
+
+/**
+ * Returns whether this point is within the extent of a line segment joining point 1 & point 2.
+ *
+ * If this point is not on the great circle defined by point1 & point 2, returns whether it is
+ * within the area bound by perpendiculars to the great circle at each point (in the same
+ * hemisphere).
+ *
+ * @param   {LatLon}  point1 - First point defining segment.
+ * @param   {LatLon}  point2 - Second point defining segment.
+ * @returns {boolean} Whether this point is within extent of segment.
+ *
+ * @example
+ *   const p1 = new LatLon(51, 1), p2 = new LatLon(52, 2);
+ *   const within1 = new LatLon(52, 1).IsWithinExtent(p1, p2); // true
+ *   const within2 = new LatLon(51, 0).IsWithinExtent(p1, p2); // false
+ */
+func (ll LatLon) IsWithinExtent(point1, point2 LatLon) bool {
+	if point1.Equals(point2) {
+		return ll.Equals(point1) // null segment
+	}
+
+	n0, n1, n2 := Vector3d(ll.toNVector()), Vector3d(point1.toNVector()), Vector3d(point2.toNVector()) // n-vectors
+
+	// get vectors representing p0->p1, p0->p2, p1->p2, p2->p1
+	δ10, δ12 := n0.Minus(n1), n2.Minus(n1)
+	δ20, δ21 := n0.Minus(n2), n1.Minus(n2)
+
+	// dot product δ10⋅δ12 tells us if p0 is on p2 side of p1, similarly for δ20⋅δ21
+	extent1 := δ10.Dot(δ12)
+	extent2 := δ20.Dot(δ21)
+
+	isSameHemisphere := n0.Dot(n1) >= 0 && n0.Dot(n2) >= 0
+
+	return extent1 >= 0 && extent2 >= 0 && isSameHemisphere
+}
+
+
+/**
+ * Returns the minimum distance from ‘this’ point to a path (polyline) defined by a series of
+ * points, and the index of the nearest segment, where segment i joins path[i] to path[i+1].
+ *
+ * A path of a single point is treated as a single null segment; for an empty path the distance is
+ * +Inf and the index -1.
+ *
+ * @param   {LatLon[]} path - Ordered points defining the path.
+ * @returns {number}   Distance in metres from this point to the nearest point on the path.
+ * @returns {number}   Index of the nearest segment.
+ *
+ * @example
+ *   const path = [ new LatLon(51, 1), new LatLon(51, 2), new LatLon(52, 2) ];
+ *   const [ d, i ] = new LatLon(51.1, 1.5).DistanceToPath(path); // 11.0 km, 0
+ */
+func (ll LatLon) DistanceToPath(path []LatLon) (float64, int) {
+	switch len(path) {
+	case 0:
+		return math.Inf(1), -1
+	case 1:
+		return ll.DistanceTo(path[0]), 0
+	}
+
+	distance, index := math.Inf(1), -1
+	for i := 0; i < len(path)-1; i++ {
+		d := ll.DistanceTo(ll.NearestPointOnSegment(path[i], path[i+1]))
+		if d < distance {
+			distance, index = d, i
+		}
+	}
+
+	return distance, index
+}
 //
 //
 ///**
@@ -792,28 +828,131 @@ func (ll LatLon) IsEnclosedBy(polygon []LatLon) bool {
 //}
 //
 //
-///**
-// * Returns point representing geographic mean of supplied points.
-// *
-// * @param   {LatLon[]} points - Array of points to be averaged.
-// * @returns {LatLon}   Point at the geographic mean of the supplied points.
-// *
-// * @example
-// *   const p = LatLon.meanOf([ new LatLon(1, 1), new LatLon(4, 2), new LatLon(1, 3) ]); // 02.0001°N, 002.0000°E
-// */
-//static meanOf(points) {
-//let m = new NvectorSpherical(0, 0, 0); // null vector
-//
-//// add all vectors
-//for (let p = 0; p < points.length; p++) {
-//m = m.plus(points[p].toNvector());
-//}
-//// m is now geographic mean
-//
-//return new NvectorSpherical(m.x, m.y, m.z).toLatLon();
-//}
-//
-//
+/**
+ * Returns point representing geographic mean of supplied points; that is, the sum of the points’
+ * n-vectors, normalised back to a latitude/longitude point. Unlike averaging latitudes and
+ * longitudes directly, this gives the expected result for points spanning the anti-meridian.
+ *
+ * If no points are supplied (or the points cancel out, e.g. a pair of antipodal points), the
+ * mean is undefined and the zero LatLon is returned.
+ *
+ * @param   {LatLon[]} points - Array of points to be averaged.
+ * @returns {LatLon}   Point at the geographic mean of the supplied points.
+ *
+ * @example
+ *   p := Centroid([]LatLon{{Lat: 1, Lon: 1}, {Lat: 4, Lon: 2}, {Lat: 1, Lon: 3}}) // 02.0001°N, 002.0000°E
+ */
+func Centroid(points []LatLon) LatLon {
+	m := Vector3d{} // null vector
+
+	// add all vectors
+	for p := range points {
+		m = m.Plus(Vector3d(points[p].toNVector()))
+	}
+	// m is now geographic mean
+
+	return NvectorSpherical(m).toLatLon()
+}
+
+/**
+ * Returns point representing weighted geographic mean of supplied points; that is, the sum of the
+ * points’ n-vectors each scaled by its weight, normalised back to a latitude/longitude point. As
+ * with Centroid, this gives the expected result for points spanning the anti-meridian.
+ *
+ * If the weighted n-vectors cancel out (e.g. no points, or all weights zero), the mean is
+ * undefined and the zero LatLon is returned.
+ *
+ * @param   {LatLon[]} points - Array of points to be averaged.
+ * @param   {number[]} weights - Weight of each point (e.g. population); weights[i] applies to points[i].
+ * @returns {LatLon}   Point at the weighted geographic mean of the supplied points.
+ * @throws  {error}    If points and weights differ in length.
+ *
+ * @example
+ *   p, err := WeightedCentroid([]LatLon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}}, []float64{3, 1}) // 00.0000°N, 002.4952°E
+ */
+func WeightedCentroid(points []LatLon, weights []float64) (LatLon, error) {
+	if len(points) != len(weights) {
+		return LatLon{}, fmt.Errorf("%d points but %d weights", len(points), len(weights))
+	}
+
+	m := Vector3d{} // null vector
+	for p := range points {
+		m = m.Plus(Vector3d(points[p].toNVector()).Times(weights[p]))
+	}
+
+	return NvectorSpherical(m).toLatLon(), nil
+}
+
+
+/**
+ * Returns the smallest circle (spherical cap) enclosing all the given points, using Welzl’s
+ * algorithm on the points’ n-vectors: a cap is bounded by the intersection of the sphere with a
+ * plane, so the cap through two points is centred on their (normalised) mean, and that through
+ * three points is centred on the normal to their plane.
+ *
+ * The points are expected to lie within a hemisphere; otherwise the result is not meaningful.
+ *
+ * @param   {LatLon[]} points - Points to be enclosed.
+ * @returns {LatLon}   Centre of enclosing circle (zero LatLon if there are no points).
+ * @returns {number}   Radius of enclosing circle, in metres.
+ *
+ * @example
+ *   const points = [ new LatLon(0, 0), new LatLon(0, 10), new LatLon(1, 5) ];
+ *   const [ centre, radius ] = LatLon.minBoundingCircle(points); // 00.0000°N, 005.0000°E; 556 km
+ */
+func MinBoundingCircle(points []LatLon) (centre LatLon, radiusMetres float64) {
+	if len(points) == 0 {
+		return LatLon{}, 0
+	}
+
+	type sphericalCap struct {
+		c    Vector3d // centre n-vector
+		cosR float64  // cosine of angular radius
+	}
+	const ε = 1e-12
+	contains := func(sc sphericalCap, v Vector3d) bool { return v.Dot(sc.c) >= sc.cosR-ε }
+	capThrough2 := func(a, b Vector3d) sphericalCap {
+		c := a.Plus(b).Unit()
+		return sphericalCap{c, a.Dot(c)}
+	}
+	capThrough3 := func(a, b, d Vector3d) sphericalCap {
+		c := b.Minus(a).Cross(d.Minus(a)).Unit()
+		if c.Dot(a) < 0 {
+			c = c.Negate()
+		}
+		return sphericalCap{c, a.Dot(c)}
+	}
+
+	v := make([]Vector3d, len(points))
+	for i := range points {
+		v[i] = Vector3d(points[i].toNVector())
+	}
+
+	sc := sphericalCap{v[0], 1}
+	for i := 1; i < len(v); i++ {
+		if contains(sc, v[i]) {
+			continue
+		}
+		sc = sphericalCap{v[i], 1}
+		for j := 0; j < i; j++ {
+			if contains(sc, v[j]) {
+				continue
+			}
+			sc = capThrough2(v[i], v[j])
+			for k := 0; k < j; k++ {
+				if !contains(sc, v[k]) {
+					sc = capThrough3(v[i], v[j], v[k])
+				}
+			}
+		}
+	}
+
+	δ := math.Acos(math.Max(-1, math.Min(1, sc.cosR)))
+
+	return NvectorSpherical(sc.c).toLatLon(), δ * earthRadius
+}
+
+
 ///**
 // * Checks if another point is equal to ‘this’ point.
 // *
@@ -915,25 +1054,44 @@ type NvectorSpherical 	Vector3d
 //}
 //
 //
-///**
-// * Converts ‘this’ n-vector to latitude/longitude point.
-// *
-// * @returns  {LatLon} Latitude/longitude point vector points to.
-// *
-// * @example
-// *   const n = new Nvector(0.5000, 0.5000, 0.7071);
-// *   const p = n.toLatLon(); // 45.0°N, 045.0°E
-// */
-//toLatLon() {
-//// tanφ = z / √(x²+y²), tanλ = y / x (same as ellipsoidal calculation)
-//
-//const x = this.x, y = this.y, z = this.z;
-//
-//const φ = Math.atan2(z, Math.sqrt(x*x + y*y));
-//const λ = Math.atan2(y, x);
-//
-//return new LatLonNvectorSpherical(φ.toDegrees(), λ.toDegrees());
-//}
+/**
+ * Converts ‘this’ n-vector to latitude/longitude point.
+ *
+ * @returns  {LatLon} Latitude/longitude point vector points to.
+ *
+ * @example
+ *   const n = new Nvector(0.5000, 0.5000, 0.7071);
+ *   const p = n.toLatLon(); // 45.0°N, 045.0°E
+ */
+func (n NvectorSpherical) toLatLon() LatLon {
+	// tanφ = z / √(x²+y²), tanλ = y / x (same as ellipsoidal calculation)
+
+	x, y, z := n.X, n.Y, n.Z
+
+	φ := math.Atan2(z, math.Sqrt(x*x+y*y))
+	λ := math.Atan2(y, x)
+
+	return LatLon{Lat: φ * toDegrees, Lon: λ * toDegrees}
+}
+
+/**
+ * Returns the n-vector of the point midway between ‘this’ n-vector and the supplied one: their
+ * (normalised) sum. Unlike the equivalent latitude/longitude calculation, this has no special
+ * cases at the poles or the anti-meridian.
+ *
+ * If the n-vectors are antipodal, the midpoint is undefined and the result is arbitrary.
+ *
+ * @param   {Nvector} other - N-vector of other point.
+ * @returns {Nvector} N-vector of midpoint.
+ *
+ * @example
+ *   const n1 = new LatLon(89.5, 0).toNvector(), n2 = new LatLon(89.5, 180).toNvector();
+ *   const p = n1.midpoint(n2).toLatLon(); // 90.0000°N
+ */
+func (n NvectorSpherical) Midpoint(other NvectorSpherical) NvectorSpherical {
+	return NvectorSpherical(Vector3d(n).Plus(Vector3d(other)).Unit())
+}
+
 //
 //
 ///**
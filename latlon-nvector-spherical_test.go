@@ -0,0 +1,183 @@
+package osgridref
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCentroid(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []LatLon
+		want   LatLon
+	}{
+		{name: "empty", points: nil, want: LatLon{}},
+		{name: "single", points: []LatLon{cambridge}, want: cambridge},
+		{name: "triangle", points: []LatLon{{Lat: 1, Lon: 1}, {Lat: 4, Lon: 2}, {Lat: 1, Lon: 3}}, want: LatLon{Lat: 2.0001, Lon: 2.0000}},
+		{name: "date line", points: []LatLon{{Lat: -1, Lon: 179}, {Lat: 1, Lon: -179}, {Lat: 1, Lon: 179}, {Lat: -1, Lon: -179}}, want: LatLon{Lat: 0, Lon: 180}},
+		{name: "date line east", points: []LatLon{{Lat: 10, Lon: 178}, {Lat: 10, Lon: -179}, {Lat: 10, Lon: 179}}, want: LatLon{Lat: 10.0023, Lon: 179.3333}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Centroid(tt.points)
+			assert.InDelta(t, tt.want.Lat, got.Lat, 1e-4)
+			// compare longitudes modulo 360°, so that 180° and -180° are equivalent
+			assert.InDelta(t, 0, Wrap180(got.Lon-tt.want.Lon), 1e-4)
+		})
+	}
+}
+
+func TestNvectorSpherical_Midpoint(t *testing.T) {
+	// across the north pole, the midpoint is the pole itself
+	n1 := LatLon{Lat: 89.5, Lon: 0}.toNVector()
+	n2 := LatLon{Lat: 89.5, Lon: 180}.toNVector()
+	m := n1.Midpoint(n2)
+	assert.InDelta(t, 1, Vector3d(m).Length(), 1e-12)
+	assert.InDelta(t, 90, m.toLatLon().Lat, 1e-9)
+
+	// symmetric, and the midpoint of a point with itself is the point
+	assert.Equal(t, m, n2.Midpoint(n1))
+	got := cambridge.toNVector().Midpoint(cambridge.toNVector()).toLatLon()
+	assert.InDelta(t, cambridge.Lat, got.Lat, 1e-12)
+	assert.InDelta(t, cambridge.Lon, got.Lon, 1e-12)
+
+	assert.Equal(t, cambridge.MidpointTo(paris), cambridge.toNVector().Midpoint(paris.toNVector()).toLatLon())
+}
+
+func TestWeightedCentroid(t *testing.T) {
+	tests := []struct {
+		name    string
+		points  []LatLon
+		weights []float64
+		want    LatLon
+	}{
+		{name: "empty", want: LatLon{}},
+		{name: "equal weights", points: []LatLon{{Lat: 1, Lon: 1}, {Lat: 4, Lon: 2}, {Lat: 1, Lon: 3}}, weights: []float64{2, 2, 2}, want: LatLon{Lat: 2.0001, Lon: 2.0000}},
+		{name: "heavier west", points: []LatLon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}}, weights: []float64{3, 1}, want: LatLon{Lat: 0, Lon: 2.4952}},
+		{name: "heavier east", points: []LatLon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}}, weights: []float64{1, 3}, want: LatLon{Lat: 0, Lon: 7.5048}},
+		{name: "zero weight ignored", points: []LatLon{cambridge, {Lat: 0, Lon: 0}}, weights: []float64{1, 0}, want: cambridge},
+		{name: "date line", points: []LatLon{{Lat: 10, Lon: 178}, {Lat: 10, Lon: -178}}, weights: []float64{1, 3}, want: LatLon{Lat: 10.0045, Lon: -178.9997}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WeightedCentroid(tt.points, tt.weights)
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want.Lat, got.Lat, 1e-4)
+			assert.InDelta(t, 0, Wrap180(got.Lon-tt.want.Lon), 1e-4)
+		})
+	}
+
+	_, err := WeightedCentroid([]LatLon{cambridge, paris}, []float64{1})
+	assert.Error(t, err)
+}
+
+func TestMinBoundingCircle(t *testing.T) {
+	tests := []struct {
+		name       string
+		points     []LatLon
+		wantCentre LatLon
+		wantRadius float64
+		onCircle   []int // indexes of points expected to lie on the circle
+	}{
+		{name: "obtuse triangle: diameter is the longest side", points: []LatLon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}, {Lat: 1, Lon: 5}},
+			wantCentre: LatLon{Lat: 0, Lon: 5}, wantRadius: 555974.6, onCircle: []int{0, 1}},
+		{name: "acute triangle: circumcircle", points: []LatLon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 10}, {Lat: 8, Lon: 5}},
+			wantCentre: LatLon{Lat: 2.438444, Lon: 5}, wantRadius: 618416.8, onCircle: []int{0, 1, 2}},
+		{name: "single point", points: []LatLon{cambridge}, wantCentre: cambridge, wantRadius: 0, onCircle: []int{0}},
+		{name: "many points", points: []LatLon{cambridge, paris, greenwich, valley, bxl, cdg},
+			wantCentre: LatLon{Lat: 51.430525, Lon: -0.391658}, wantRadius: 346481.1, onCircle: []int{1, 3, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			centre, radius := MinBoundingCircle(tt.points)
+			assert.InDelta(t, tt.wantCentre.Lat, centre.Lat, 1e-6)
+			assert.InDelta(t, tt.wantCentre.Lon, centre.Lon, 1e-6)
+			assert.InDelta(t, tt.wantRadius, radius, 0.1)
+			for i, p := range tt.points {
+				assert.LessOrEqual(t, centre.DistanceTo(p), radius+1e-6, "point %d enclosed", i)
+			}
+			for _, i := range tt.onCircle {
+				assert.InDelta(t, radius, centre.DistanceTo(tt.points[i]), 1e-6, "point %d on circle", i)
+			}
+		})
+	}
+
+	centre, radius := MinBoundingCircle(nil)
+	assert.Equal(t, LatLon{}, centre)
+	assert.Equal(t, 0.0, radius)
+}
+
+func TestLatLon_IsEnclosedBy(t *testing.T) {
+	bounds := []LatLon{{Lat: 45, Lon: 1}, {Lat: 45, Lon: 2}, {Lat: 46, Lon: 2}, {Lat: 46, Lon: 1}}
+	tests := []struct {
+		name  string
+		point LatLon
+		want  bool
+	}{
+		{name: "inside", point: LatLon{Lat: 45.1, Lon: 1.1}, want: true},
+		{name: "centre", point: LatLon{Lat: 45.5, Lon: 1.5}, want: true},
+		{name: "north", point: LatLon{Lat: 50, Lon: 1.1}, want: false},
+		{name: "west", point: LatLon{Lat: 45.5, Lon: 0.9}, want: false},
+		{name: "far away", point: LatLon{Lat: -45.5, Lon: -178.5}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.point.IsEnclosedBy(bounds))
+		})
+	}
+}
+
+func TestLatLon_NearestPointOnSegment(t *testing.T) {
+	p1 := LatLon{Lat: 51.0, Lon: 1.0}
+	p2 := LatLon{Lat: 51.0, Lon: 2.0}
+
+	got := LatLon{Lat: 51.0, Lon: 1.9}.NearestPointOnSegment(p1, p2)
+	assert.InDelta(t, 51.0004, got.Lat, 5e-5)
+	assert.InDelta(t, 1.9000, got.Lon, 5e-5)
+	assert.InDelta(t, 42.71, got.DistanceTo(LatLon{Lat: 51.0, Lon: 1.9}), 0.01)
+
+	assert.Equal(t, p2, LatLon{Lat: 51.0, Lon: 2.1}.NearestPointOnSegment(p1, p2))
+	assert.Equal(t, p1, LatLon{Lat: 51.0, Lon: 0.5}.NearestPointOnSegment(p1, p2))
+	assert.Equal(t, p1, cambridge.NearestPointOnSegment(p1, p1))
+}
+
+func TestLatLon_IsWithinExtent(t *testing.T) {
+	p1 := LatLon{Lat: 51, Lon: 1}
+	p2 := LatLon{Lat: 52, Lon: 2}
+	assert.True(t, LatLon{Lat: 52, Lon: 1}.IsWithinExtent(p1, p2))
+	assert.False(t, LatLon{Lat: 51, Lon: 0}.IsWithinExtent(p1, p2))
+	assert.True(t, p1.IsWithinExtent(p1, p1))
+	assert.False(t, p2.IsWithinExtent(p1, p1))
+}
+
+func TestLatLon_DistanceToPath(t *testing.T) {
+	path := []LatLon{{Lat: 51, Lon: 1}, {Lat: 51, Lon: 2}, {Lat: 52, Lon: 2}}
+
+	tests := []struct {
+		name      string
+		point     LatLon
+		path      []LatLon
+		wantDist  float64
+		wantIndex int
+	}{
+		{name: "mid first segment", point: LatLon{Lat: 51.1, Lon: 1.5}, path: path, wantDist: 11000.8, wantIndex: 0},
+		{name: "mid second segment", point: LatLon{Lat: 51.5, Lon: 2.2}, path: path, wantDist: 13844.1, wantIndex: 1},
+		{name: "nearest vertex", point: LatLon{Lat: 50.9, Lon: 2.1}, path: path, wantDist: LatLon{Lat: 50.9, Lon: 2.1}.DistanceTo(path[1]), wantIndex: 0},
+		{name: "beyond end", point: LatLon{Lat: 53, Lon: 2}, path: path, wantDist: LatLon{Lat: 53, Lon: 2}.DistanceTo(path[2]), wantIndex: 1},
+		{name: "on path", point: path[0], path: path, wantDist: 0, wantIndex: 0},
+		{name: "single point", point: cambridge, path: []LatLon{paris}, wantDist: cambridge.DistanceTo(paris), wantIndex: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, i := tt.point.DistanceToPath(tt.path)
+			assert.InDelta(t, tt.wantDist, d, 0.1)
+			assert.Equal(t, tt.wantIndex, i)
+		})
+	}
+
+	d, i := cambridge.DistanceToPath(nil)
+	assert.True(t, math.IsInf(d, 1))
+	assert.Equal(t, -1, i)
+}
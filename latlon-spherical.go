@@ -3,6 +3,10 @@ package osgridref
 import (
     "fmt"
     "math"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
 )
 
 /* - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -  */
@@ -13,11 +17,33 @@ import (
 /* - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -  */
 
 const (
-	π                     = math.Pi
-	metresToKm            = 1.0 / 1000
-	metresToMiles         = 1.0 / 1609.344
-	metresToNauticalMiles = 1.0 / 1852
-	earthRadius           = 6_371_000.0 // Its equatorial radius is 6378 km, but its polar radius is 6357 km
+	π           = math.Pi
+	earthRadius = 6_371_000.0 // Its equatorial radius is 6378 km, but its polar radius is 6357 km
+
+	// distance from the antipode within which IsAntipodalTo considers points antipodal, metres
+	antipodalTolerance = 100.0
+
+	// most segments GreatCircleTo and RhumbLineTo will divide a path into
+	maxPathSegments = 1_000_000
+)
+
+// Conversion factors from metres, as returned by DistanceTo etc; e.g. d * MetresToMiles gives d
+// in statute miles.
+const (
+	MetresToKm            = 1.0 / 1000
+	MetresToMiles         = 1.0 / 1609.344
+	MetresToNauticalMiles = 1.0 / 1852
+)
+
+var (
+	rangeBearingFormat = regexp.MustCompile(`^(\d+(?:\.\d*)?)\s*/\s*(\d+(?:\.\d*)?)\s*(KM|M|NM|MI)?$`)
+	rangeUnits         = map[string]float64{
+		"":   1,
+		"M":  1,
+		"KM": 1 / MetresToKm,
+		"NM": 1 / MetresToNauticalMiles,
+		"MI": 1 / MetresToMiles,
+	}
 )
 
 
@@ -86,11 +112,271 @@ func (ll LatLon) DistanceTo(point LatLon) float64 {
 }
 
 
+/**
+ * A point prepared as the origin of many distance calculations, with the trigonometric values
+ * depending only on it precomputed; created by AsOrigin.
+ */
+type Origin struct {
+    φ1, λ1, cosφ1 float64
+}
+
+
+/**
+ * Returns ‘this’ point prepared as the origin for repeated distance calculations, such as finding
+ * the distances from one fixed point to many targets.
+ *
+ * @returns {Origin} This point as an origin.
+ *
+ * @example
+ *   const origin = new LatLon(52.205, 0.119).asOrigin();
+ *   const d = origin.distanceTo(new LatLon(48.857, 2.351)); // 404.3×10³ m
+ */
+func (ll LatLon) AsOrigin() Origin {
+    φ1 := ll.Lat * toRadians
+    return Origin{φ1: φ1, λ1: ll.Lon * toRadians, cosφ1: math.Cos(φ1)}
+}
+
+
+/**
+ * Returns the distance along the surface of the earth from the origin to destination point; the
+ * result is identical to that of LatLon.DistanceTo, but cheaper to compute.
+ *
+ * @param   {LatLon} point - Latitude/longitude of destination point.
+ * @returns {number} Distance between the origin and destination point, in metres.
+ */
+func (o Origin) DistanceTo(point LatLon) float64 {
+    φ2 := point.Lat * toRadians
+    λ2 := point.Lon * toRadians
+    sinΔφ := math.Sin((φ2 - o.φ1) / 2)
+    sinΔλ := math.Sin((λ2 - o.λ1) / 2)
+
+    a := sinΔφ*sinΔφ + o.cosφ1*math.Cos(φ2)*sinΔλ*sinΔλ
+    c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+    return earthRadius * c
+}
+
+
+/**
+ * Returns the distance along the surface of the earth from ‘this’ point to destination point,
+ * using the spherical law of cosines: d = acos(sinφ1·sinφ2 + cosφ1·cosφ2·cosΔλ) · R.
+ *
+ * This agrees with the haversine formula used by DistanceTo, but is ill-conditioned for small
+ * distances: the cosine of a small angle is very close to 1, so distances of less than a metre or
+ * so are badly affected by rounding. It is provided mainly for comparison.
+ *
+ * @param   {LatLon} point - Latitude/longitude of destination point.
+ * @returns {number} Distance between this point and destination point, in metres.
+ *
+ * @example
+ *   const p1 = new LatLon(52.205, 0.119);
+ *   const p2 = new LatLon(48.857, 2.351);
+ *   const d = p1.distanceToLawOfCosines(p2); // 404.3×10³ m
+ */
+func (ll LatLon) DistanceToLawOfCosines(point LatLon) float64 {
+    φ1 := ll.Lat * toRadians
+    φ2 := point.Lat * toRadians
+    Δλ := (point.Lon - ll.Lon) * toRadians
+
+    cosδ := math.Sin(φ1)*math.Sin(φ2) + math.Cos(φ1)*math.Cos(φ2)*math.Cos(Δλ)
+    cosδ = math.Max(-1, math.Min(1, cosδ)) // rounding can take it just outside acos's domain
+    δ := math.Acos(cosδ)
+
+    return δ * earthRadius
+}
+
+
+/**
+ * Returns the distance from ‘this’ point to destination point, as DistanceTo, but checking both
+ * points first: latitudes must be within ±90° and longitudes within ±180°, so NaN and infinite
+ * values are rejected rather than propagating into the result.
+ *
+ * @param   {LatLon} point - Latitude/longitude of destination point.
+ * @returns {number} Distance between this point and destination point, in metres.
+ * @throws  {Error}  Invalid point.
+ *
+ * @example
+ *   const p1 = new LatLon(52.205, 0.119);
+ *   const p2 = new LatLon(48.857, 2.351);
+ *   const d = p1.distanceToChecked(p2); // 404.3 km
+ */
+func (ll LatLon) DistanceToChecked(point LatLon) (float64, error) {
+    if err := ll.check(); err != nil {
+        return 0, err
+    }
+    if err := point.check(); err != nil {
+        return 0, err
+    }
+
+    return ll.DistanceTo(point), nil
+}
+
+
+// check returns an error if ll's latitude is not within ±90° or its longitude within ±180°
+// (including if either is NaN).
+func (ll LatLon) check() error {
+    if !(ll.Lat >= -90 && ll.Lat <= 90) {
+        return fmt.Errorf("%w: latitude %v in %v", ErrOutOfRange, ll.Lat, ll)
+    }
+    if !(ll.Lon >= -180 && ll.Lon <= 180) {
+        return fmt.Errorf("%w: longitude %v in %v", ErrOutOfRange, ll.Lon, ll)
+    }
+    return nil
+}
+
+
+/**
+ * Returns a copy of ‘this’ point with the latitude wrapped into the range ±90° (using Wrap90) and
+ * the longitude into ±180° (using Wrap180), e.g. for cleaning up input which may be out of range.
+ *
+ * Latitude and longitude are wrapped independently: a latitude carried past a pole is reflected
+ * back (95° => 85°) but the longitude is NOT moved to the other side of the earth, as it would be
+ * in the convention where (95°, 10°) means the point (85°, -170°). Callers which need that
+ * behaviour should adjust the longitude themselves before normalizing.
+ *
+ * @returns {LatLon} Normalized point.
+ *
+ * @example
+ *   const p = new LatLon(95, 190).normalized(); // 85°N, 170°W
+ */
+func (ll LatLon) Normalized() LatLon {
+    return LatLon{Lat: Wrap90(ll.Lat), Lon: Wrap180(ll.Lon)}
+}
+
+
+/**
+ * Tests whether ‘this’ point is within the given distance of the supplied point (along the surface
+ * of the earth, as given by DistanceTo).
+ *
+ * @param   {LatLon}  point - Latitude/longitude of the other point.
+ * @param   {number}  metres - Maximum distance, in metres.
+ * @returns {boolean} Whether the points are no more than metres apart.
+ *
+ * @example
+ *   const p1 = new LatLon(52.205, 0.119);
+ *   const p2 = new LatLon(48.857, 2.351);
+ *   const near = p1.isWithinDistance(p2, 500e3); // true (404.3 km)
+ */
+func (ll LatLon) IsWithinDistance(point LatLon, metres float64) bool {
+    return ll.DistanceTo(point) <= metres
+}
+
+
+/**
+ * Tests whether ‘this’ point is (nearly) antipodal to the supplied point, i.e. diametrically
+ * opposite it on the earth, to within antipodalTolerance (100 metres). Between antipodal points
+ * there is no unique great circle, so calculations such as Intersection are ill-conditioned, and
+ * become unreliable well before the points are exactly antipodal.
+ *
+ * @param   {LatLon}  other - Latitude/longitude of the other point.
+ * @returns {boolean} Whether other is within 100 metres of the antipode of this point.
+ *
+ * @example
+ *   const p1 = new LatLon(20, 30);
+ *   const p2 = new LatLon(-20, -150);
+ *   const antipodal = p1.isAntipodalTo(p2); // true
+ */
+func (ll LatLon) IsAntipodalTo(other LatLon) bool {
+    antipode := LatLon{Lat: -other.Lat, Lon: Wrap180(other.Lon + 180)}
+    return ll.DistanceTo(antipode) < antipodalTolerance
+}
+
+
+/**
+ * Returns an approximate distance from ‘this’ point to destination point, using the
+ * equirectangular (flat-earth) projection: x = Δλ⋅cos(φm), y = Δφ, d = R⋅√(x² + y²).
+ *
+ * This is considerably cheaper than DistanceTo, and is suitable for coarse comparisons such as
+ * sorting candidate points by distance; it is only accurate for short distances (an error of
+ * well under 1% at 100 km in mid-latitudes), becoming poor over long distances or near the poles.
+ *
+ * @param   {LatLon} point - Latitude/longitude of destination point.
+ * @returns {number} Approximate distance between this point and destination point, in metres.
+ *
+ * @example
+ *   const p1 = new LatLon(52.205, 0.119);
+ *   const p2 = new LatLon(48.857, 2.351);
+ *   const d = p1.approxDistanceTo(p2); // 404.3×10³ m
+ */
+func (ll LatLon) ApproxDistanceTo(point LatLon) float64 {
+    R := earthRadius
+    φ1 := ll.Lat * toRadians
+    φ2 := point.Lat * toRadians
+    Δφ := φ2 - φ1
+    Δλ := Wrap180(point.Lon-ll.Lon) * toRadians // take shorter route across the anti-meridian
+
+    x := Δλ * math.Cos((φ1+φ2)/2)
+    y := Δφ
+    d := R * math.Sqrt(x*x+y*y)
+
+    return d
+}
+
+
+/**
+ * Returns the candidate point closest to ‘this’ point, together with its index in candidates and
+ * its distance from ‘this’ point.
+ *
+ * @param   {LatLon[]} candidates - Points to be searched.
+ * @returns {LatLon}   Closest candidate point (zero LatLon if there are no candidates).
+ * @returns {number}   Index of closest candidate point (-1 if there are no candidates).
+ * @returns {number}   Distance to closest candidate point in metres (+Inf if there are no candidates).
+ *
+ * @example
+ *   const p = new LatLon(52.205, 0.119);
+ *   const [nearest, i, d] = p.nearest([ new LatLon(48.857, 2.351), new LatLon(51.47788, -0.00147) ]); // 1; 81.3 km
+ */
+func (ll LatLon) Nearest(candidates []LatLon) (LatLon, int, float64) {
+    nearest, index, distance := LatLon{}, -1, math.Inf(1)
+    for i := range candidates {
+        d := ll.DistanceTo(candidates[i])
+        if d < distance {
+            nearest, index, distance = candidates[i], i, d
+        }
+    }
+
+    return nearest, index, distance
+}
+
+
+/**
+ * Returns the points with near-duplicates removed: each point within epsilonMetres of an earlier
+ * retained point is dropped, so that each cluster of points (such as repeated noisy GPS fixes of one
+ * position) is represented by its first member. Order is otherwise preserved.
+ *
+ * Points are compared against every retained point, so this takes time proportional to the number
+ * of points times the number retained.
+ *
+ * @param   {LatLon[]} points - Points to be de-duplicated.
+ * @param   {number}   epsilonMetres - Distance within which points are considered duplicates.
+ * @returns {LatLon[]} Retained points.
+ *
+ * @example
+ *   const fixes = [ new LatLon(52.205, 0.119), new LatLon(52.20501, 0.11901), new LatLon(48.857, 2.351) ];
+ *   const unique = LatLon.uniquePoints(fixes, 5); // 52.205,0.119 48.857,2.351
+ */
+func UniquePoints(points []LatLon, epsilonMetres float64) []LatLon {
+    var unique []LatLon
+    for _, p := range points {
+        if _, _, d := p.Nearest(unique); d > epsilonMetres {
+            unique = append(unique, p)
+        }
+    }
+
+    return unique
+}
+
+
 /**
  * Returns the initial bearing from ‘this’ point to destination point.
  *
+ * From the north pole every direction is south, so the bearing is always 180°; similarly from the
+ * south pole the bearing is always 0°. The bearing to the north pole is always 0°, and to the south
+ * pole 180°. Coincident points (including two points at the same pole) have no defined bearing, and
+ * NaN is returned, as for RhumbBearingTo.
+ *
  * @param   {LatLon} point - Latitude/longitude of destination point.
- * @returns {number} Initial bearing in degrees from north (0°..360°).
+ * @returns {number} Initial bearing in degrees from north (0°..360°), or NaN if coincident.
  *
  * @example
  *   const p1 = new LatLon(52.205, 0.119);
@@ -101,6 +387,23 @@ func (ll LatLon) InitialBearingTo(point LatLon) float64 {
     // tanθ = sinΔλ⋅cosφ2 / cosφ1⋅sinφ2 − sinφ1⋅cosφ2⋅cosΔλ
     // see mathforum.org/library/drmath/view/55417.html for derivation
 
+    if ll.coincident(point) {
+        return math.NaN()
+    }
+
+    // at the poles longitude is meaningless, and cosφ is not quite zero, so the formula would
+    // give a bearing depending on Δλ
+    switch {
+    case ll.Lat >= 90:
+        return 180
+    case ll.Lat <= -90:
+        return 0
+    case point.Lat >= 90:
+        return 0
+    case point.Lat <= -90:
+        return 180
+    }
+
     φ1 := ll.Lat * toRadians
     φ2 := point.Lat * toRadians
     Δλ := (point.Lon - ll.Lon) * toRadians
@@ -118,8 +421,12 @@ func (ll LatLon) InitialBearingTo(point LatLon) float64 {
  * Returns final bearing arriving at destination point from ‘this’ point; the final bearing will
  * differ from the initial bearing by varying degrees according to distance and latitude.
  *
+ * The pole conventions are those of InitialBearingTo: arriving at the north pole the bearing is
+ * always 0°, arriving at the south pole 180°; leaving the north pole it is 180°, leaving the south
+ * pole 0°. Coincident points have no defined bearing, and NaN is returned.
+ *
  * @param   {LatLon} point - Latitude/longitude of destination point.
- * @returns {number} Final bearing in degrees from north (0°..360°).
+ * @returns {number} Final bearing in degrees from north (0°..360°), or NaN if coincident.
  *
  * @example
  *   const p1 = new LatLon(52.205, 0.119);
@@ -127,6 +434,10 @@ func (ll LatLon) InitialBearingTo(point LatLon) float64 {
  *   const b2 = p1.finalBearingTo(p2); // 157.9°
  */
 func (ll LatLon) FinalBearingTo(point LatLon) float64 {
+    if ll.coincident(point) {
+        return math.NaN()
+    }
+
     // get initial bearing from destination point to this point & reverse it by adding 180°
 
     bearing := point.InitialBearingTo(ll) + 180
@@ -135,6 +446,56 @@ func (ll LatLon) FinalBearingTo(point LatLon) float64 {
 }
 
 
+/**
+ * Returns the signed angle between the initial bearing from ‘this’ point to one point and the
+ * initial bearing from ‘this’ point to another; i.e. the turn required at ‘this’ point to change
+ * from heading towards ‘from’ to heading towards ‘to’. If either point is coincident with ‘this’
+ * point, the turn is undefined and NaN is returned.
+ *
+ * @param   {LatLon} from - Point currently being headed towards.
+ * @param   {LatLon} to - Point to be headed towards after turning.
+ * @returns {number} Turn angle in degrees (-180°..+180°); positive is a clockwise turn (to the right).
+ *
+ * @example
+ *   const p = new LatLon(52.205, 0.119);
+ *   const north = new LatLon(53.205, 0.119);
+ *   const east = new LatLon(52.205, 1.119);
+ *   const turn = p.turnAngle(north, east); // 89.6°
+ */
+func (ll LatLon) TurnAngle(from, to LatLon) float64 {
+    θ1 := ll.InitialBearingTo(from)
+    θ2 := ll.InitialBearingTo(to)
+
+    return Wrap180(θ2 - θ1)
+}
+
+
+/**
+ * Tests whether a point lies within the sector centred on the given bearing from ‘this’ point,
+ * i.e. whether the initial bearing to the point is within halfAngle of centreBearing, allowing for
+ * sectors that straddle north. A point coincident with ‘this’ point is within any sector.
+ *
+ * @param   {LatLon}  point - Point to be tested.
+ * @param   {number}  centreBearing - Bearing of the centre of the sector, in degrees from north.
+ * @param   {number}  halfAngle - Half the angular width of the sector, in degrees.
+ * @returns {bool}    True if the point lies within the sector (including on its edges).
+ *
+ * @example
+ *   const p = new LatLon(52.205, 0.119);
+ *   const north = new LatLon(53.205, 0.119);
+ *   const inSector = p.isInSector(north, 350, 20); // true
+ */
+func (ll LatLon) IsInSector(point LatLon, centreBearing, halfAngle float64) bool {
+    if ll.coincident(point) {
+        return true
+    }
+
+    θ := ll.InitialBearingTo(point)
+
+    return math.Abs(Wrap180(θ-centreBearing)) <= halfAngle
+}
+
+
 /**
  * Returns the midpoint between ‘this’ point and destination point.
  *
@@ -146,31 +507,12 @@ func (ll LatLon) FinalBearingTo(point LatLon) float64 {
  *   const p2 = new LatLon(48.857, 2.351);
  *   const pMid = p1.midpointTo(p2); // 50.5363°N, 001.2746°E
  */
-//func (ll LatLon) MidpointTo(point LatLon) LatLon{
-//    // φm = atan2( sinφ1 + sinφ2, √( (cosφ1 + cosφ2⋅cosΔλ)² + cos²φ2⋅sin²Δλ ) )
-//    // λm = λ1 + atan2(cosφ2⋅sinΔλ, cosφ1 + cosφ2⋅cosΔλ)
-//    // midpoint is sum of vectors to two points: mathforum.org/library/drmath/view/51822.html
-//
-//    φ1 := ll.Lat* toRadians;
-//    λ1 := ll.Lon* toRadians;
-//    φ2 := point.Lat* toRadians;
-//    Δλ := (point.Lon - ll.Lon)* toRadians;
-//
-//    // get cartesian coordinates for the two points
-//    A := { x: math.Cos(φ1), y: 0, z: math.Sin(φ1) }; // place point A on prime meridian y=0
-//    B := { x: math.Cos(φ2)*math.Cos(Δλ), y: math.Cos(φ2)*math.Sin(Δλ), z: math.Sin(φ2) };
-//
-//// vector to midpoint is sum of vectors to two points (no need to normalise)
-//    C := { x: A.x + B.x, y: A.y + B.y, z: A.z + B.z };
-//
-//    φm := math.Atan2(C.z, math.Sqrt(C.x*C.x + C.y*C.y));
-//    λm := λ1 + math.Atan2(C.y, C.x);
-//
-//    lat := φm* toDegrees;
-//    lon := λm* toDegrees;
-//
-//    return LatLon{Lat: lat, Lon: lon}
-//}
+func (ll LatLon) MidpointTo(point LatLon) LatLon {
+    // midpoint is sum of vectors to two points: mathforum.org/library/drmath/view/51822.html;
+    // working with n-vectors avoids any special handling of the poles or the anti-meridian
+
+    return ll.toNVector().Midpoint(point.toNVector()).toLatLon()
+}
 
 
 /**
@@ -185,35 +527,75 @@ func (ll LatLon) FinalBearingTo(point LatLon) float64 {
  *   const p2 = new LatLon(48.857, 2.351);
  *   const pInt = p1.intermediatePointTo(p2, 0.25); // 51.3721°N, 000.7073°E
  */
-//intermediatePointTo(point, fraction) {
-//if (!(point instanceof LatLon)) point = LatLon.parse(point); // allow literal forms
-//if (this.equals(point)) return new LatLon(ll.Lat, ll.Lon); // coincident points
-//
-//    φ1 = ll.Lat* toRadians, λ1 = ll.Lon* toRadians;
-//    φ2 = point.lat* toRadians, λ2 = point.lon* toRadians;
-//
-//// distance between points
-//    Δφ = φ2 - φ1;
-//    Δλ = λ2 - λ1;
-//    a = math.Sin(Δφ/2) * math.Sin(Δφ/2)
-//+ math.Cos(φ1) * math.Cos(φ2) * math.Sin(Δλ/2) * math.Sin(Δλ/2);
-//    δ = 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a));
-//
-//    A = math.Sin((1-fraction)*δ) / math.Sin(δ);
-//    B = math.Sin(fraction*δ) / math.Sin(δ);
-//
-//    x = A * math.Cos(φ1) * math.Cos(λ1) + B * math.Cos(φ2) * math.Cos(λ2);
-//    y = A * math.Cos(φ1) * math.Sin(λ1) + B * math.Cos(φ2) * math.Sin(λ2);
-//    z = A * math.Sin(φ1) + B * math.Sin(φ2);
-//
-//    φ3 = math.Atan2(z, math.Sqrt(x*x + y*y));
-//    λ3 = math.Atan2(y, x);
-//
-//    lat = φ3* toDegrees;
-//    lon = λ3* toDegrees;
-//
-//return new LatLon(lat, lon);
-//}
+func (ll LatLon) IntermediatePointTo(point LatLon, fraction float64) LatLon {
+    if ll == point {
+        return ll // coincident points
+    }
+
+    φ1, λ1 := ll.Lat*toRadians, ll.Lon*toRadians
+    φ2, λ2 := point.Lat*toRadians, point.Lon*toRadians
+
+    // distance between points
+    Δφ := φ2 - φ1
+    Δλ := λ2 - λ1
+    a := math.Sin(Δφ/2)*math.Sin(Δφ/2) +
+        math.Cos(φ1)*math.Cos(φ2)*math.Sin(Δλ/2)*math.Sin(Δλ/2)
+    δ := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+
+    A := math.Sin((1-fraction)*δ) / math.Sin(δ)
+    B := math.Sin(fraction*δ) / math.Sin(δ)
+
+    x := A*math.Cos(φ1)*math.Cos(λ1) + B*math.Cos(φ2)*math.Cos(λ2)
+    y := A*math.Cos(φ1)*math.Sin(λ1) + B*math.Cos(φ2)*math.Sin(λ2)
+    z := A*math.Sin(φ1) + B*math.Sin(φ2)
+
+    φ3 := math.Atan2(z, math.Sqrt(x*x+y*y))
+    λ3 := math.Atan2(y, x)
+
+    lat := φ3 * toDegrees
+    lon := λ3 * toDegrees
+
+    return LatLon{Lat: lat, Lon: lon}
+}
+
+
+/**
+ * Returns points along the great circle path from ‘this’ point to the given point, such that no
+ * two consecutive points are more than maxSegmentMetres apart. Both endpoints are included; if
+ * maxSegmentMetres is not a positive finite distance, they are the only points returned. The path
+ * is divided into at most a million segments, so if maxSegmentMetres is tiny, consecutive points
+ * may be further apart than it.
+ *
+ * This is intended for rendering great-circle arcs as a series of short straight segments.
+ *
+ * @param   {LatLon}   point - Latitude/longitude of destination point.
+ * @param   {number}   maxSegmentMetres - Maximum distance between consecutive points, in metres.
+ * @returns {LatLon[]} Points along the path, starting with this point and ending with point.
+ *
+ * @example
+ *   const p1 = new LatLon(52.205, 0.119);
+ *   const p2 = new LatLon(48.857, 2.351);
+ *   const route = p1.greatCircleTo(p2, 50e3); // 10 points, 9 segments of 44.9 km
+ */
+func (ll LatLon) GreatCircleTo(point LatLon, maxSegmentMetres float64) []LatLon {
+    d := ll.DistanceTo(point)
+    if d == 0 || !(maxSegmentMetres > 0) || math.IsInf(maxSegmentMetres, 1) {
+        return []LatLon{ll, point}
+    }
+
+    segments := maxPathSegments
+    if n := math.Ceil(d / maxSegmentMetres); n < maxPathSegments {
+        segments = int(n)
+    }
+    route := make([]LatLon, segments+1)
+    route[0] = ll
+    for i := 1; i < segments; i++ {
+        route[i] = ll.IntermediatePointTo(point, float64(i)/float64(segments))
+    }
+    route[segments] = point
+
+    return route
+}
 
 
 /**
@@ -223,7 +605,7 @@ func (ll LatLon) FinalBearingTo(point LatLon) float64 {
  * @param   {number} distance - Distance travelled, in same units as earth radius (default: metres).
  * @param   {number} bearing - Initial bearing in degrees from north.
  * @param   {number} [radius=6371e3] - (Mean) radius of earth (defaults to radius in metres).
- * @returns {LatLon} Destination point.
+ * @returns {LatLon} Destination point, with longitude normalised to -180°..+180°.
  *
  * @example
  *   const p1 = new LatLon(51.47788, -0.00147);
@@ -241,59 +623,205 @@ func (ll LatLon) DestinationPoint(distance float64, bearing float64) LatLon {
     λ1 := ll.Lon * toRadians
 
     sinφ2 := math.Sin(φ1)*math.Cos(δ) + math.Cos(φ1)*math.Sin(δ)*math.Cos(θ)
+    sinφ2 = math.Max(-1, math.Min(1, sinφ2)) // rounding can take it just outside asin's domain at the poles
     φ2 := math.Asin(sinφ2)
     y := math.Sin(θ) * math.Sin(δ) * math.Cos(φ1)
     x := math.Cos(δ) - math.Sin(φ1)*sinφ2
     λ2 := λ1 + math.Atan2(y, x)
 
     lat := φ2 * toDegrees
-    lon := λ2 * toDegrees
+    lon := Wrap180(λ2 * toDegrees)
 
     return LatLon{Lat: lat, Lon: lon}
 }
 
 
 /**
- * Returns the point of intersection of two paths defined by point and bearing.
+ * Returns the destination point from ‘this’ point given a compact "bearing/range" string, as
+ * commonly used in aviation and military contexts.
  *
- * @param   {LatLon}      p1 - First point.
- * @param   {number}      brng1 - Initial bearing from first point.
- * @param   {LatLon}      p2 - Second point.
- * @param   {number}      brng2 - Initial bearing from second point.
- * @returns {LatLon|null} Destination point (null if no unique intersection defined).
+ * The bearing is in degrees from north (0..360); the range is followed by an optional unit, one of
+ * "m" (metres, the default), "km", "nm" (nautical miles) or "mi" (statute miles).
+ *
+ * @param   {string} s - Bearing and range, e.g. "045/12.5km".
+ * @returns {LatLon} Destination point.
+ * @throws  {Error}  Invalid bearing/range string.
  *
  * @example
- *   const p1 = new LatLon(51.8853, 0.2545), brng1 = 108.547;
- *   const p2 = new LatLon(49.0034, 2.5735), brng2 =  32.435;
- *   const pInt = LatLon.intersection(p1, brng1, p2, brng2); // 50.9078°N, 004.5084°E
+ *   const p1 = new LatLon(51.47788, -0.00147);
+ *   const p2 = p1.destinationFromRangeBearing('300.7/7.794km'); // 51.5136°N, 000.0983°W
  */
-func Intersection(p1 LatLon, brng1 float64, p2 LatLon, brng2 float64) (LatLon, bool) {
-    // see www.edwilliams.org/avform.htm#Intersection
-
-    φ1, λ1 := p1.Lat*toRadians, p1.Lon*toRadians
-    φ2, λ2 := p2.Lat*toRadians, p2.Lon*toRadians
-    θ13, θ23 := brng1*toRadians, brng2*toRadians
-    Δφ := φ2 - φ1
-    Δλ := λ2 - λ1
+func (ll LatLon) DestinationFromRangeBearing(s string) (LatLon, error) {
+    match := rangeBearingFormat.FindStringSubmatch(strings.ToUpper(strings.TrimSpace(s)))
+    if match == nil {
+        return LatLon{}, fmt.Errorf("invalid bearing/range: '%s'", s)
+    }
 
-    // angular distance p1-p2
-    δ12 := 2 * math.Asin(math.Sqrt(math.Sin(Δφ/2)*math.Sin(Δφ/2)+math.Cos(φ1)*math.Cos(φ2)*math.Sin(Δλ/2)*math.Sin(Δλ/2)))
-    if math.Abs(δ12) <= math.SmallestNonzeroFloat64 {
-        return p1, true
+    bearing, err := strconv.ParseFloat(match[1], 64)
+    if err != nil || bearing > 360 {
+        return LatLon{}, fmt.Errorf("invalid bearing/range: '%s'", s)
+    }
+    distance, err := strconv.ParseFloat(match[2], 64)
+    if err != nil {
+        return LatLon{}, fmt.Errorf("invalid bearing/range: '%s'", s)
     }
 
-    // initial/final bearings between points
-    cosθa := (math.Sin(φ2) - math.Sin(φ1)*math.Cos(δ12)) / (math.Sin(δ12) * math.Cos(φ1))
-    cosθb := (math.Sin(φ1) - math.Sin(φ2)*math.Cos(δ12)) / (math.Sin(δ12) * math.Cos(φ2))
-    θa := math.Acos(math.Min(math.Max(cosθa, -1), 1)) // protect against rounding errors
-    θb := math.Acos(math.Min(math.Max(cosθb, -1), 1)) // protect against rounding errors
+    return ll.DestinationPoint(distance*rangeUnits[match[3]], bearing), nil
+}
 
-    θ12 := θa
-    if math.Sin(λ2-λ1) <= 0 {
-        θ12 = 2*π - θa
-    }
 
-    θ21 := 2*π - θb
+/**
+ * Returns the destination point from ‘this’ point having travelled the given distance on the
+ * given initial bearing, together with the final bearing on arrival at the destination.
+ *
+ * This is useful when chaining destination-point calculations along a great circle, where the
+ * bearing varies along the path. The final bearing is that of the path travelled, which for a
+ * distance beyond half the circumference differs from FinalBearingTo the destination.
+ *
+ * @param   {number} distance - Distance travelled, in same units as earth radius (default: metres).
+ * @param   {number} bearing - Initial bearing in degrees from north.
+ * @returns {LatLon} Destination point.
+ * @returns {number} Final bearing in degrees from north (0°..360°).
+ *
+ * @example
+ *   const p1 = new LatLon(51.47788, -0.00147);
+ *   const [p2, b2] = p1.destinationPointAndBearing(7794, 300.7); // 51.5136°N, 000.0983°W; 300.6°
+ */
+func (ll LatLon) DestinationPointAndBearing(distance float64, bearing float64) (LatLon, float64) {
+    dest := ll.DestinationPoint(distance, bearing)
+    if distance == 0 || ll.coincident(dest) {
+        // no path travelled, so bearing is unchanged
+        return dest, Wrap360(bearing)
+    }
+
+    // azimuth at the destination along the path travelled, which (for distances beyond half the
+    // circumference) is not the shorter great circle to dest that FinalBearingTo would use
+    φ1 := ll.Lat * toRadians
+    θ := bearing * toRadians
+    δ := distance / earthRadius
+    y := math.Sin(θ) * math.Cos(φ1)
+    x := math.Cos(δ)*math.Cos(φ1)*math.Cos(θ) - math.Sin(φ1)*math.Sin(δ)
+
+    return dest, Wrap360(math.Atan2(y, x) * toDegrees)
+}
+
+
+/**
+ * Returns a fan of points at the given distance from ‘this’ point, at count evenly spaced bearings
+ * running clockwise from bearingStart to bearingEnd inclusive. If bearingEnd is less than
+ * bearingStart the fan runs clockwise through north.
+ *
+ * @param   {number}   bearingStart - Bearing of the first point, in degrees from north.
+ * @param   {number}   bearingEnd - Bearing of the last point, in degrees from north.
+ * @param   {number}   count - Number of points (a single point is at bearingStart).
+ * @param   {number}   distance - Distance travelled to each point, in metres.
+ * @returns {LatLon[]} Points, in order of bearing.
+ *
+ * @example
+ *   const p = new LatLon(52.205, 0.119);
+ *   const semicircle = p.fan(270, 90, 7, 1000); // points at 270°, 300°, 330°, 0°, 30°, 60°, 90°
+ */
+func (ll LatLon) Fan(bearingStart, bearingEnd float64, count int, distance float64) []LatLon {
+    if count <= 0 {
+        return nil
+    }
+
+    span := bearingEnd - bearingStart
+    if span < 0 {
+        span += 360 // wrap through north
+    }
+
+    points := make([]LatLon, count)
+    for i := range points {
+        bearing := bearingStart
+        if count > 1 {
+            bearing += span * float64(i) / float64(count-1)
+        }
+        points[i] = ll.DestinationPoint(distance, Wrap360(bearing))
+    }
+
+    return points
+}
+
+
+/**
+ * Returns the point offset from ‘this’ point by the given distances east and north, using the
+ * equirectangular (flat-earth) approximation.
+ *
+ * This is intended for small adjustments, such as placing labels; it is only accurate for offsets
+ * of up to a few kilometres, and becomes poor near the poles.
+ *
+ * @param   {number} east - Distance east in metres (negative for west).
+ * @param   {number} north - Distance north in metres (negative for south).
+ * @returns {LatLon} Offset point.
+ *
+ * @example
+ *   const p1 = new LatLon(51.47788, -0.00147);
+ *   const p2 = p1.offsetMetres(100, 100); // 51.4788°N, 000.0000°W
+ */
+func (ll LatLon) OffsetMetres(east, north float64) LatLon {
+    φ := ll.Lat * toRadians
+
+    Δφ := north / earthRadius
+    Δλ := east / (earthRadius * math.Cos(φ))
+
+    lat := ll.Lat + Δφ*toDegrees
+    lon := ll.Lon + Δλ*toDegrees
+
+    return LatLon{Lat: lat, Lon: Wrap180(lon)}
+}
+
+
+/**
+ * Returns the point of intersection of two paths defined by point and bearing.
+ *
+ * Two great circles cross at two antipodal points; the one returned lies ahead of both start
+ * points (within half a circumference of each, in its direction of travel). If each path heads
+ * towards a different one of the points, i.e. the paths only cross behind one or other start
+ * point, there is no unique intersection. Nor is there if the start points are (nearly)
+ * antipodal, as given by IsAntipodalTo: every great circle through one passes through the other.
+ *
+ * @param   {LatLon}      p1 - First point.
+ * @param   {number}      brng1 - Initial bearing from first point.
+ * @param   {LatLon}      p2 - Second point.
+ * @param   {number}      brng2 - Initial bearing from second point.
+ * @returns {LatLon|null} Destination point (null if no unique intersection defined).
+ *
+ * @example
+ *   const p1 = new LatLon(51.8853, 0.2545), brng1 = 108.547;
+ *   const p2 = new LatLon(49.0034, 2.5735), brng2 =  32.435;
+ *   const pInt = LatLon.intersection(p1, brng1, p2, brng2); // 50.9078°N, 004.5084°E
+ */
+func Intersection(p1 LatLon, brng1 float64, p2 LatLon, brng2 float64) (LatLon, bool) {
+    // see www.edwilliams.org/avform.htm#Intersection
+
+    φ1, λ1 := p1.Lat*toRadians, p1.Lon*toRadians
+    φ2, λ2 := p2.Lat*toRadians, p2.Lon*toRadians
+    θ13, θ23 := brng1*toRadians, brng2*toRadians
+    Δφ := φ2 - φ1
+    Δλ := λ2 - λ1
+
+    // angular distance p1-p2
+    δ12 := 2 * math.Asin(math.Sqrt(math.Sin(Δφ/2)*math.Sin(Δφ/2)+math.Cos(φ1)*math.Cos(φ2)*math.Sin(Δλ/2)*math.Sin(Δλ/2)))
+    if math.Abs(δ12) <= math.SmallestNonzeroFloat64 {
+        return p1, true
+    }
+    if p1.IsAntipodalTo(p2) {
+        return LatLon{}, false
+    }
+
+    // initial/final bearings between points
+    cosθa := (math.Sin(φ2) - math.Sin(φ1)*math.Cos(δ12)) / (math.Sin(δ12) * math.Cos(φ1))
+    cosθb := (math.Sin(φ1) - math.Sin(φ2)*math.Cos(δ12)) / (math.Sin(δ12) * math.Cos(φ2))
+    θa := math.Acos(math.Min(math.Max(cosθa, -1), 1)) // protect against rounding errors
+    θb := math.Acos(math.Min(math.Max(cosθb, -1), 1)) // protect against rounding errors
+
+    θ12 := θa
+    if math.Sin(λ2-λ1) <= 0 {
+        θ12 = 2*π - θa
+    }
+
+    θ21 := 2*π - θb
     if math.Sin(λ2-λ1) <= 0 {
         θ21 = θb
     }
@@ -301,8 +829,8 @@ func Intersection(p1 LatLon, brng1 float64, p2 LatLon, brng2 float64) (LatLon, b
     α1 := θ13 - θ12 // angle 2-1-3
     α2 := θ21 - θ23 // angle 1-2-3
 
-    if math.Sin(α1) == 0 && math.Sin(α2) == 0 {
-        // infinite intersections
+    if math.Abs(math.Sin(α1)) < 1e-12 && math.Abs(math.Sin(α2)) < 1e-12 {
+        // infinite intersections (allowing for rounding errors)
         return LatLon{}, false
     }
 
@@ -327,38 +855,83 @@ func Intersection(p1 LatLon, brng1 float64, p2 LatLon, brng2 float64) (LatLon, b
 }
 
 
-///**
-// * Returns (signed) distance from ‘this’ point to great circle defined by start-point and
-// * end-point.
-// *
-// * @param   {LatLon} pathStart - Start point of great circle path.
-// * @param   {LatLon} pathEnd - End point of great circle path.
-// * @param   {number} [radius=6371e3] - (Mean) radius of earth (defaults to radius in metres).
-// * @returns {number} Distance to great circle (-ve if to left, +ve if to right of path).
-// *
-// * @example
-// *   const pCurrent = new LatLon(53.2611, -0.7972);
-// *   const p1 = new LatLon(53.3206, -1.7297);
-// *   const p2 = new LatLon(53.1887, 0.1334);
-// *   const d = pCurrent.crossTrackDistanceTo(p1, p2);  // -307.5 m
-// */
-//crossTrackDistanceTo(pathStart, pathEnd, radius=6371e3) {
-//if (!(pathStart instanceof LatLon)) pathStart = LatLon.parse(pathStart); // allow literal forms
-//if (!(pathEnd instanceof LatLon)) pathEnd = LatLon.parse(pathEnd);       // allow literal forms
-//    R = radius;
-//
-//if (this.equals(pathStart)) return 0;
-//
-//    δ13 = pathStart.distanceTo(this, R) / R;
-//    θ13 = pathStart.initialBearingTo(this)* toRadians;
-//    θ12 = pathStart.initialBearingTo(pathEnd)* toRadians;
-//
-//    δxt = math.Asin(math.Sin(δ13) * math.Sin(θ13 - θ12));
-//
-//return δxt * R;
-//}
-//
-//
+/**
+ * Returns the point of intersection of two paths defined by point and bearing that lies ahead of
+ * both start points, for applications such as conflict detection where a crossing behind either
+ * start point is of no interest. This is the intersection returned by Intersection, which never
+ * returns a point behind either start point; ok is false if the paths only cross behind, lie on
+ * the same great circle, or start from (nearly) antipodal points.
+ *
+ * @param   {LatLon} p1 - First point.
+ * @param   {number} brng1 - Initial bearing from first point.
+ * @param   {LatLon} p2 - Second point.
+ * @param   {number} brng2 - Initial bearing from second point.
+ * @returns {LatLon} Intersection point ahead of both points.
+ * @returns {bool}   False if there is no such point.
+ *
+ * @example
+ *   const p1 = new LatLon(51.8853, 0.2545), brng1 = 108.547;
+ *   const p2 = new LatLon(49.0034, 2.5735), brng2 =  32.435;
+ *   const pInt = LatLon.forwardIntersection(p1, brng1, p2, brng2); // 50.9078°N, 004.5084°E
+ */
+func ForwardIntersection(p1 LatLon, brng1 float64, p2 LatLon, brng2 float64) (LatLon, bool) {
+    return Intersection(p1, brng1, p2, brng2)
+}
+
+
+/**
+ * Returns (signed) distance from ‘this’ point to great circle defined by start-point and
+ * end-point. If the start and end points coincide, the great circle is undefined and NaN is
+ * returned.
+ *
+ * @param   {LatLon} pathStart - Start point of great circle path.
+ * @param   {LatLon} pathEnd - End point of great circle path.
+ * @returns {number} Distance to great circle in metres (-ve if to left, +ve if to right of path).
+ *
+ * @example
+ *   const pCurrent = new LatLon(53.2611, -0.7972);
+ *   const p1 = new LatLon(53.3206, -1.7297);
+ *   const p2 = new LatLon(53.1887, 0.1334);
+ *   const d = pCurrent.crossTrackDistanceTo(p1, p2);  // -307.5 m
+ */
+func (ll LatLon) CrossTrackDistanceTo(pathStart, pathEnd LatLon) float64 {
+    return ll.CrossTrackDistanceToRadius(pathStart, pathEnd, earthRadius)
+}
+
+
+/**
+ * Returns (signed) distance from ‘this’ point to great circle defined by start-point and
+ * end-point, on an earth of the given radius.
+ *
+ * @param   {LatLon} pathStart - Start point of great circle path.
+ * @param   {LatLon} pathEnd - End point of great circle path.
+ * @param   {number} radius - (Mean) radius of earth.
+ * @returns {number} Distance to great circle (-ve if to left, +ve if to right of path), in same
+ *                   units as radius.
+ *
+ * @example
+ *   const pCurrent = new LatLon(53.2611, -0.7972);
+ *   const p1 = new LatLon(53.3206, -1.7297);
+ *   const p2 = new LatLon(53.1887, 0.1334);
+ *   const d = pCurrent.crossTrackDistanceTo(p1, p2, 3959);  // -0.1911 miles
+ */
+func (ll LatLon) CrossTrackDistanceToRadius(pathStart, pathEnd LatLon, radius float64) float64 {
+    R := radius
+
+    if ll.coincident(pathStart) {
+        return 0
+    }
+
+    δ13 := pathStart.DistanceTo(ll) / earthRadius // angular distance, independent of radius
+    θ13 := pathStart.InitialBearingTo(ll) * toRadians
+    θ12 := pathStart.InitialBearingTo(pathEnd) * toRadians
+
+    δxt := math.Asin(math.Sin(δ13) * math.Sin(θ13-θ12))
+
+    return δxt * R
+}
+
+
 ///**
 // * Returns how far ‘this’ point is along a path from from start-point, heading towards end-point.
 // * That is, if a perpendicular is drawn from ‘this’ point to the (great circle) path, the
@@ -417,159 +990,516 @@ func Intersection(p1 LatLon, brng1 float64, p2 LatLon, brng2 float64) (LatLon, b
 //}
 //
 //
-///**
-// * Returns the pair of meridians at which a great circle defined by two points crosses the given
-// * latitude. If the great circle doesn't reach the given latitude, null is returned.
-// *
-// * @param   {LatLon}      point1 - First point defining great circle.
-// * @param   {LatLon}      point2 - Second point defining great circle.
-// * @param   {number}      latitude - Latitude crossings are to be determined for.
-// * @returns {Object|null} Object containing { lon1, lon2 } or null if given latitude not reached.
-// */
-//static crossingParallels(point1, point2, latitude) {
-//if (point1.equals(point2)) return null; // coincident points
-//
-//    φ = Number(latitude)* toRadians;
-//
-//    φ1 = point1.lat* toRadians;
-//    λ1 = point1.lon* toRadians;
-//    φ2 = point2.lat* toRadians;
-//    λ2 = point2.lon* toRadians;
-//
-//    Δλ = λ2 - λ1;
-//
-//    x = math.Sin(φ1) * math.Cos(φ2) * math.Cos(φ) * math.Sin(Δλ);
-//    y = math.Sin(φ1) * math.Cos(φ2) * math.Cos(φ) * math.Cos(Δλ) - math.Cos(φ1) * math.Sin(φ2) * math.Cos(φ);
-//    z = math.Cos(φ1) * math.Cos(φ2) * math.Sin(φ) * math.Sin(Δλ);
-//
-//if (z * z > x * x + y * y) return null; // great circle doesn't reach latitude
-//
-//    λm = math.Atan2(-y, x);               // longitude at max latitude
-//    Δλi = math.Acos(z / math.Sqrt(x*x + y*y)); // Δλ from λm to intersection points
-//
-//    λi1 = λ1 + λm - Δλi;
-//    λi2 = λ1 + λm + Δλi;
-//
-//    lon1 = λi1* toDegrees;
-//    lon2 = λi2* toDegrees;
-//
-//return {
-//lon1: Dms.wrap180(lon1),
-//lon2: Dms.wrap180(lon2),
-//};
-//}
+/**
+ * Returns the pair of meridians at which a great circle defined by two points crosses the given
+ * latitude. If the great circle doesn't reach the given latitude, ok is false.
+ *
+ * @param   {LatLon}  point1 - First point defining great circle.
+ * @param   {LatLon}  point2 - Second point defining great circle.
+ * @param   {number}  latitude - Latitude crossings are to be determined for.
+ * @returns {number}  lon1, lon2 - Longitudes of the crossings, -180..+180.
+ * @returns {bool}    ok - False if the points are coincident or the latitude is not reached.
+ *
+ * @example
+ *   const p1 = new LatLon(0, 0);
+ *   const p2 = new LatLon(60, 30);
+ *   const { lon1, lon2 } = LatLon.crossingParallels(p1, p2, 30); // 9.594°, 170.406°
+ */
+func CrossingParallels(point1, point2 LatLon, latitude float64) (lon1, lon2 float64, ok bool) {
+    if point1.Equals(point2) {
+        return 0, 0, false // coincident points
+    }
+
+    φ := latitude * toRadians
+
+    φ1 := point1.Lat * toRadians
+    λ1 := point1.Lon * toRadians
+    φ2 := point2.Lat * toRadians
+    λ2 := point2.Lon * toRadians
+
+    Δλ := λ2 - λ1
+
+    x := math.Sin(φ1) * math.Cos(φ2) * math.Cos(φ) * math.Sin(Δλ)
+    y := math.Sin(φ1)*math.Cos(φ2)*math.Cos(φ)*math.Cos(Δλ) - math.Cos(φ1)*math.Sin(φ2)*math.Cos(φ)
+    z := math.Cos(φ1) * math.Cos(φ2) * math.Sin(φ) * math.Sin(Δλ)
+
+    if z*z > x*x+y*y {
+        return 0, 0, false // great circle doesn't reach latitude
+    }
+
+    λm := math.Atan2(-y, x)                    // longitude at max latitude
+    Δλi := math.Acos(z / math.Sqrt(x*x+y*y)) // Δλ from λm to intersection points
+
+    λi1 := λ1 + λm - Δλi
+    λi2 := λ1 + λm + Δλi
+
+    return Wrap180(λi1 * toDegrees), Wrap180(λi2 * toDegrees), true
+}
+
+
+/**
+ * Returns the latitude at which a great circle defined by two points crosses the given meridian.
+ * If the great circle is itself a meridian (or the points are coincident), ok is false.
+ *
+ * @param   {LatLon}  point1 - First point defining great circle.
+ * @param   {LatLon}  point2 - Second point defining great circle.
+ * @param   {number}  longitude - Longitude the crossing is to be determined for.
+ * @returns {number}  lat - Latitude of the crossing.
+ * @returns {bool}    ok - False if there is no single crossing.
+ */
+func crossingMeridian(point1, point2 LatLon, longitude float64) (lat float64, ok bool) {
+    // see www.edwilliams.org/avform.htm#Int
+    φ1 := point1.Lat * toRadians
+    λ1 := point1.Lon * toRadians
+    φ2 := point2.Lat * toRadians
+    λ2 := point2.Lon * toRadians
+    λ := longitude * toRadians
+
+    d := math.Cos(φ1) * math.Cos(φ2) * math.Sin(λ1-λ2)
+    if math.Abs(d) < 1e-15 {
+        return 0, false
+    }
+
+    φ := math.Atan((math.Sin(φ1)*math.Cos(φ2)*math.Sin(λ-λ2) - math.Sin(φ2)*math.Cos(φ1)*math.Sin(λ-λ1)) / d)
+
+    return φ * toDegrees, true
+}
+
+
+/**
+ * Clips the great circle segment from start to end to the lat/lon box with south-west corner sw and
+ * north-east corner ne, using the points at which the segment crosses the box's parallels and
+ * meridians.
+ *
+ * The box must not span the antimeridian (sw.Lon ≤ ne.Lon). A segment bulging poleward may leave
+ * the box through its northern or southern edge and re-enter it; the clipped segment then runs from
+ * where it first enters the box to where it finally leaves it.
+ *
+ * @param   {LatLon} start - Start of the segment.
+ * @param   {LatLon} end - End of the segment.
+ * @param   {LatLon} sw - South-west corner of the box.
+ * @param   {LatLon} ne - North-east corner of the box.
+ * @returns {LatLon} Start of the visible part of the segment.
+ * @returns {LatLon} End of the visible part of the segment.
+ * @returns {bool}   Whether any part of the segment lies within the box.
+ *
+ * @example
+ *   const [ p1, p2, visible ] = LatLon.clipSegment(new LatLon(0, -5), new LatLon(0, 5),
+ *       new LatLon(-1, -1), new LatLon(1, 1)); // 0°N, 1°W; 0°N, 1°E; true
+ */
+func ClipSegment(start, end, sw, ne LatLon) (LatLon, LatLon, bool) {
+    const ε = 1e-9 // degrees
+    inBox := func(p LatLon) bool {
+        return p.Lat >= sw.Lat-ε && p.Lat <= ne.Lat+ε && p.Lon >= sw.Lon-ε && p.Lon <= ne.Lon+ε
+    }
+
+    if start.Equals(end) {
+        return start, end, inBox(start)
+    }
+
+    // candidate end points of the clipped segment, with their distance along the segment
+    type candidate struct {
+        p LatLon
+        d float64
+    }
+    var candidates []candidate
+    length := start.DistanceTo(end)
+    add := func(p LatLon) {
+        if !inBox(p) {
+            return
+        }
+        d := start.DistanceTo(p)
+        if d+p.DistanceTo(end)-length > 1e-3 { // not on the segment
+            return
+        }
+        candidates = append(candidates, candidate{p, d})
+    }
+
+    add(start)
+    add(end)
+    for _, lat := range []float64{sw.Lat, ne.Lat} {
+        if lon1, lon2, ok := CrossingParallels(start, end, lat); ok {
+            add(LatLon{Lat: lat, Lon: lon1})
+            add(LatLon{Lat: lat, Lon: lon2})
+        }
+    }
+    for _, lon := range []float64{sw.Lon, ne.Lon} {
+        if lat, ok := crossingMeridian(start, end, lon); ok {
+            add(LatLon{Lat: lat, Lon: lon})
+        }
+    }
+
+    if len(candidates) == 0 {
+        return LatLon{}, LatLon{}, false
+    }
+
+    first, last := candidates[0], candidates[0]
+    for _, c := range candidates[1:] {
+        if c.d < first.d {
+            first = c
+        }
+        if c.d > last.d {
+            last = c
+        }
+    }
+
+    return first.p, last.p, true
+}
+
+
+/* Rhumb - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -  */
+
+
+/**
+ * Returns the distance travelling from ‘this’ point to destination point along a rhumb line.
+ *
+ * Points on the same meridian give the exact meridional arc; coincident points give 0.
+ *
+ * @param   {LatLon} point - Latitude/longitude of destination point.
+ * @returns {number} Distance in metres between this point and destination point.
+ *
+ * @example
+ *   const p1 = new LatLon(51.127, 1.338);
+ *   const p2 = new LatLon(50.964, 1.853);
+ *   const d = p1.rhumbDistanceTo(p2); //  40.31 km
+ */
+func (ll LatLon) RhumbDistanceTo(point LatLon) float64 {
+    return ll.RhumbDistanceToRadius(point, earthRadius)
+}
+
+
+/**
+ * Returns the distance travelling from ‘this’ point to destination point along a rhumb line, on
+ * an earth of the given radius.
+ *
+ * @param   {LatLon} point - Latitude/longitude of destination point.
+ * @param   {number} radius - (Mean) radius of earth.
+ * @returns {number} Distance between this point and destination point, in same units as radius.
+ *
+ * @example
+ *   const p1 = new LatLon(51.127, 1.338);
+ *   const p2 = new LatLon(50.964, 1.853);
+ *   const d = p1.rhumbDistanceTo(p2, 3959); //  25.05 miles
+ */
+func (ll LatLon) RhumbDistanceToRadius(point LatLon, radius float64) float64 {
+    // see www.edwilliams.org/avform.htm#Rhumb
+
+    R := radius
+    φ1 := ll.Lat * toRadians
+    φ2 := point.Lat * toRadians
+    Δφ := φ2 - φ1
+    Δλ := math.Abs(point.Lon-ll.Lon) * toRadians
+    // if dLon over 180° take shorter rhumb line across the anti-meridian:
+    if math.Abs(Δλ) > π {
+        if Δλ > 0 {
+            Δλ = -(2*π - Δλ)
+        } else {
+            Δλ = 2*π + Δλ
+        }
+    }
+
+    // same meridian: the rhumb line is the meridian itself
+    if Δλ == 0 {
+        return math.Abs(Δφ) * R
+    }
+
+    // on Mercator projection, longitude distances shrink by latitude; q is the 'stretch factor'
+    // q becomes ill-conditioned along E-W line (0/0); use empirical tolerance to avoid it
+    Δψ := math.Log(math.Tan(φ2/2+π/4) / math.Tan(φ1/2+π/4))
+    q := math.Cos(φ1)
+    if math.Abs(Δψ) > 10e-12 {
+        q = Δφ / Δψ
+    }
+
+    // distance is pythagoras on 'stretched' Mercator projection, √(Δφ² + q²·Δλ²)
+    δ := math.Sqrt(Δφ*Δφ + q*q*Δλ*Δλ) // angular distance in radians
+    d := δ * R
+
+    return d
+}
+
+
+/**
+ * Returns the distance travelling from ‘this’ point to destination point along a rhumb line, in
+ * nautical miles (of 1852 metres).
+ *
+ * @param   {LatLon} point - Latitude/longitude of destination point.
+ * @returns {number} Distance in nautical miles between this point and destination point.
+ *
+ * @example
+ *   const p1 = new LatLon(51.127, 1.338);
+ *   const p2 = new LatLon(50.964, 1.853);
+ *   const d = p1.rhumbDistanceNM(p2); //  21.76 NM
+ */
+func (ll LatLon) RhumbDistanceNM(point LatLon) float64 {
+    return ll.RhumbDistanceTo(point) * MetresToNauticalMiles
+}
+
+
+/**
+ * Returns the bearing from ‘this’ point to destination point along a rhumb line.
+ *
+ * Points on the same meridian give exactly 0 (due north) or 180 (due south); coincident points,
+ * which have no defined bearing, give NaN.
+ *
+ * @param   {LatLon}    point - Latitude/longitude of destination point.
+ * @returns {number}    Bearing in degrees from north (NaN for coincident points).
+ *
+ * @example
+ *   const p1 = new LatLon(51.127, 1.338);
+ *   const p2 = new LatLon(50.964, 1.853);
+ *   const d = p1.rhumbBearingTo(p2); // 116.7°
+ */
+func (ll LatLon) RhumbBearingTo(point LatLon) float64 {
+    if ll.coincident(point) {
+        return math.NaN() // coincident points
+    }
+
+    φ1 := ll.Lat * toRadians
+    φ2 := point.Lat * toRadians
+    Δλ := (point.Lon - ll.Lon) * toRadians
+    // if dLon over 180° take shorter rhumb line across the anti-meridian:
+    if math.Abs(Δλ) > π {
+        if Δλ > 0 {
+            Δλ = -(2*π - Δλ)
+        } else {
+            Δλ = 2*π + Δλ
+        }
+    }
+
+    // same meridian: due north or due south
+    if Δλ == 0 {
+        if φ2 > φ1 {
+            return 0
+        }
+        return 180
+    }
+
+    Δψ := math.Log(math.Tan(φ2/2+π/4) / math.Tan(φ1/2+π/4))
+
+    θ := math.Atan2(Δλ, Δψ)
+
+    bearing := θ * toDegrees
+
+    return Wrap360(bearing)
+}
+
+
+/**
+ * Returns the rhumb line bearings between every pair of the given points: element [i][j] is the
+ * bearing from points[i] to points[j], as given by RhumbBearingTo (to within rounding errors).
+ * Each point's Mercator-projected latitude is calculated just once, rather than once per pair,
+ * making this considerably faster than calling RhumbBearingTo for each pair.
+ *
+ * @param   {LatLon[]}   points - Points to calculate bearings between.
+ * @returns {number[][]} Matrix of bearings in degrees from north (NaN for coincident points,
+ *   including on the diagonal).
+ *
+ * @example
+ *   const points = [ new LatLon(51.127, 1.338), new LatLon(50.964, 1.853) ];
+ *   const m = LatLon.rhumbBearingMatrix(points); // [ [ NaN, 116.7 ], [ 296.7, NaN ] ]
+ */
+func RhumbBearingMatrix(points []LatLon) [][]float64 {
+    // Mercator-projected latitudes, ψ = ln(tan(π/4 + φ/2))
+    ψ := make([]float64, len(points))
+    for i, p := range points {
+        ψ[i] = math.Log(math.Tan(p.Lat*toRadians/2 + π/4))
+    }
+
+    m := make([][]float64, len(points))
+    for i, p1 := range points {
+        m[i] = make([]float64, len(points))
+        for j, p2 := range points {
+            if p1.coincident(p2) {
+                m[i][j] = math.NaN() // coincident points
+                continue
+            }
+
+            Δλ := (p2.Lon - p1.Lon) * toRadians
+            // if dLon over 180° take shorter rhumb line across the anti-meridian:
+            if math.Abs(Δλ) > π {
+                if Δλ > 0 {
+                    Δλ = -(2*π - Δλ)
+                } else {
+                    Δλ = 2*π + Δλ
+                }
+            }
+
+            // same meridian: due north or due south
+            if Δλ == 0 {
+                if p2.Lat > p1.Lat {
+                    m[i][j] = 0
+                } else {
+                    m[i][j] = 180
+                }
+                continue
+            }
+
+            m[i][j] = Wrap360(math.Atan2(Δλ, ψ[j]-ψ[i]) * toDegrees)
+        }
+    }
+
+    return m
+}
+
+
+/**
+ * Returns the destination point having travelled along a rhumb line from ‘this’ point the given
+ * distance on the given bearing.
+ *
+ * @param   {number} distance - Distance travelled, in same units as earth radius (default: metres).
+ * @param   {number} bearing - Bearing in degrees from north.
+ * @returns {LatLon} Destination point.
+ *
+ * @example
+ *   const p1 = new LatLon(51.127, 1.338);
+ *   const p2 = p1.rhumbDestinationPoint(40300, 116.7); // 50.9642°N, 001.8530°E
+ */
+func (ll LatLon) RhumbDestinationPoint(distance float64, bearing float64) LatLon {
+    φ1, λ1 := ll.Lat*toRadians, ll.Lon*toRadians
+    θ := bearing * toRadians
+
+    δ := distance / earthRadius // angular distance in radians
+
+    Δφ := δ * math.Cos(θ)
+    φ2 := φ1 + Δφ
+
+    // check for some daft bugger going past the pole, normalise latitude if so
+    if math.Abs(φ2) > π/2 {
+        if φ2 > 0 {
+            φ2 = π - φ2
+        } else {
+            φ2 = -π - φ2
+        }
+    }
+
+    Δψ := math.Log(math.Tan(φ2/2+π/4) / math.Tan(φ1/2+π/4))
+    q := math.Cos(φ1) // E-W course becomes ill-conditioned with 0/0
+    if math.Abs(Δψ) > 10e-12 {
+        q = Δφ / Δψ
+    }
+
+    Δλ := δ * math.Sin(θ) / q
+    λ2 := λ1 + Δλ
+
+    lat := φ2 * toDegrees
+    lon := λ2 * toDegrees
+
+    return LatLon{Lat: lat, Lon: lon}
+}
+
+
+/**
+ * Returns the distance between the destination reached by travelling from ‘this’ point along a
+ * great circle with the given initial bearing, and that reached by travelling the same distance
+ * along a rhumb line with the same (constant) bearing.
+ *
+ * The divergence is zero for north-south paths (where great circle and rhumb line coincide), and
+ * grows with distance and latitude for east-west paths.
+ *
+ * @param   {number} distance - Distance travelled, in metres.
+ * @param   {number} bearing - Initial bearing in degrees from north.
+ * @returns {number} Distance between the two destination points, in metres.
+ *
+ * @example
+ *   const p1 = new LatLon(60, 0);
+ *   const d = p1.routeDivergence(1000e3, 90); // 135.4 km
+ */
+func (ll LatLon) RouteDivergence(distance float64, bearing float64) float64 {
+    greatCircle := ll.DestinationPoint(distance, bearing)
+    rhumb := ll.RhumbDestinationPoint(distance, bearing)
+
+    return greatCircle.DistanceTo(rhumb)
+}
+
+
+/**
+ * Returns (signed) distance from ‘this’ point to the rhumb line through the given start and end
+ * points, analogously to CrossTrackDistanceTo for great circles.
+ *
+ * Rhumb lines are straight on the Mercator projection, which is conformal; so the foot of the
+ * perpendicular from ‘this’ point to the path is found in the projected plane, and the distance
+ * is the rhumb distance to it. Longitudes are taken relative to the start point across the
+ * shorter side of the anti-meridian.
+ *
+ * @param   {LatLon} pathStart - Start point of rhumb line path.
+ * @param   {LatLon} pathEnd - End point of rhumb line path.
+ * @returns {number} Distance to rhumb line in metres (-ve if to left, +ve if to right of path),
+ *                   or NaN if the start and end points are coincident.
+ *
+ * @example
+ *   const p = new LatLon(50.1, 5);
+ *   const d = p.rhumbCrossTrackDistanceTo(new LatLon(50, 0), new LatLon(50, 10)); // -11.12 km
+ */
+func (ll LatLon) RhumbCrossTrackDistanceTo(pathStart, pathEnd LatLon) float64 {
+    ψ := func(lat float64) float64 { return math.Log(math.Tan(lat*toRadians/2 + π/4)) } // Mercator y
+
+    // Mercator coordinates (in radians) relative to the path start
+    x12, y12 := Wrap180(pathEnd.Lon-pathStart.Lon)*toRadians, ψ(pathEnd.Lat)-ψ(pathStart.Lat)
+    x13, y13 := Wrap180(ll.Lon-pathStart.Lon)*toRadians, ψ(ll.Lat)-ψ(pathStart.Lat)
+
+    len2 := x12*x12 + y12*y12
+    if len2 == 0 {
+        return math.NaN() // coincident path points
+    }
+
+    // foot of the perpendicular from this point to the path
+    t := (x13*x12 + y13*y12) / len2
+    ψf := ψ(pathStart.Lat) + t*y12
+    foot := LatLon{
+        Lat: (2*math.Atan(math.Exp(ψf)) - π/2) * toDegrees,
+        Lon: Wrap180(pathStart.Lon + t*x12*toDegrees),
+    }
+
+    d := ll.RhumbDistanceTo(foot)
+    if x12*y13-y12*x13 > 0 {
+        return -d // to the left
+    }
+    return d
+}
+
+
+/**
+ * Returns points along the rhumb line from ‘this’ point to the given point, evenly spaced such
+ * that no two consecutive points are more than maxSegmentMetres apart. Both endpoints are
+ * included; if maxSegmentMetres is not a positive finite distance, they are the only points
+ * returned. As with GreatCircleTo, the path is divided into at most a million segments.
+ *
+ * As a rhumb line is a line of constant bearing (a straight line on a Mercator projection), the
+ * rhumb bearing between consecutive points is (nearly) constant.
+ *
+ * @param   {LatLon}   point - Latitude/longitude of destination point.
+ * @param   {number}   maxSegmentMetres - Maximum distance between consecutive points, in metres.
+ * @returns {LatLon[]} Points along the path, starting with this point and ending with point.
+ *
+ * @example
+ *   const p1 = new LatLon(51.127, 1.338);
+ *   const p2 = new LatLon(50.964, 1.853);
+ *   const route = p1.rhumbLineTo(p2, 10e3); // 6 points, 5 segments of 8.06 km
+ */
+func (ll LatLon) RhumbLineTo(point LatLon, maxSegmentMetres float64) []LatLon {
+    d := ll.RhumbDistanceTo(point)
+    if d == 0 || !(maxSegmentMetres > 0) || math.IsInf(maxSegmentMetres, 1) {
+        return []LatLon{ll, point}
+    }
+
+    bearing := ll.RhumbBearingTo(point)
+    segments := maxPathSegments
+    if n := math.Ceil(d / maxSegmentMetres); n < maxPathSegments {
+        segments = int(n)
+    }
+    route := make([]LatLon, segments+1)
+    route[0] = ll
+    for i := 1; i < segments; i++ {
+        p := ll.RhumbDestinationPoint(d*float64(i)/float64(segments), bearing)
+        route[i] = LatLon{Lat: p.Lat, Lon: Wrap180(p.Lon)}
+    }
+    route[segments] = point
+
+    return route
+}
 
 
-///* Rhumb - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - -  */
-//
-//
-///**
-// * Returns the distance travelling from ‘this’ point to destination point along a rhumb line.
-// *
-// * @param   {LatLon} point - Latitude/longitude of destination point.
-// * @param   {number} [radius=6371e3] - (Mean) radius of earth (defaults to radius in metres).
-// * @returns {number} Distance in km between this point and destination point (same units as radius).
-// *
-// * @example
-// *   const p1 = new LatLon(51.127, 1.338);
-// *   const p2 = new LatLon(50.964, 1.853);
-// *   const d = p1.distanceTo(p2); //  40.31 km
-// */
-//rhumbDistanceTo(point, radius=6371e3) {
-//if (!(point instanceof LatLon)) point = LatLon.parse(point); // allow literal forms
-//
-//// see www.edwilliams.org/avform.htm#Rhumb
-//
-//    R = radius;
-//    φ1 = ll.Lat* toRadians;
-//    φ2 = point.lat* toRadians;
-//    Δφ = φ2 - φ1;
-//let Δλ = Math.abs(point.lon - ll.Lon)* toRadians;
-//// if dLon over 180° take shorter rhumb line across the anti-meridian:
-//if (Math.abs(Δλ) > π) Δλ = Δλ > 0 ? -(2 * π - Δλ) : (2 * π + Δλ);
-//
-//// on Mercator projection, longitude distances shrink by latitude; q is the 'stretch factor'
-//// q becomes ill-conditioned along E-W line (0/0); use empirical tolerance to avoid it
-//    Δψ = Math.log(math.Tan(φ2 / 2 + π / 4) / math.Tan(φ1 / 2 + π / 4));
-//    q = Math.abs(Δψ) > 10e-12 ? Δφ / Δψ : math.Cos(φ1);
-//
-//// distance is pythagoras on 'stretched' Mercator projection, √(Δφ² + q²·Δλ²)
-//    δ = math.Sqrt(Δφ*Δφ + q*q * Δλ*Δλ); // angular distance in radians
-//    d = δ * R;
-//
-//return d;
-//}
-//
-//
-///**
-// * Returns the bearing from ‘this’ point to destination point along a rhumb line.
-// *
-// * @param   {LatLon}    point - Latitude/longitude of destination point.
-// * @returns {number}    Bearing in degrees from north.
-// *
-// * @example
-// *   const p1 = new LatLon(51.127, 1.338);
-// *   const p2 = new LatLon(50.964, 1.853);
-// *   const d = p1.rhumbBearingTo(p2); // 116.7°
-// */
-//rhumbBearingTo(point) {
-//if (!(point instanceof LatLon)) point = LatLon.parse(point); // allow literal forms
-//if (this.equals(point)) return NaN; // coincident points
-//
-//    φ1 = ll.Lat* toRadians;
-//    φ2 = point.lat* toRadians;
-//let Δλ = (point.lon - ll.Lon)* toRadians;
-//// if dLon over 180° take shorter rhumb line across the anti-meridian:
-//if (Math.abs(Δλ) > π) Δλ = Δλ > 0 ? -(2 * π - Δλ) : (2 * π + Δλ);
-//
-//    Δψ = Math.log(math.Tan(φ2 / 2 + π / 4) / math.Tan(φ1 / 2 + π / 4));
-//
-//    θ = math.Atan2(Δλ, Δψ);
-//
-//    bearing = θ* toDegrees;
-//
-//return Dms.wrap360(bearing);
-//}
-//
-//
-///**
-// * Returns the destination point having travelled along a rhumb line from ‘this’ point the given
-// * distance on the given bearing.
-// *
-// * @param   {number} distance - Distance travelled, in same units as earth radius (default: metres).
-// * @param   {number} bearing - Bearing in degrees from north.
-// * @param   {number} [radius=6371e3] - (Mean) radius of earth (defaults to radius in metres).
-// * @returns {LatLon} Destination point.
-// *
-// * @example
-// *   const p1 = new LatLon(51.127, 1.338);
-// *   const p2 = p1.rhumbDestinationPoint(40300, 116.7); // 50.9642°N, 001.8530°E
-// */
-//rhumbDestinationPoint(distance, bearing, radius=6371e3) {
-//    φ1 = ll.Lat* toRadians, λ1 = ll.Lon* toRadians;
-//    θ = Number(bearing)* toRadians;
-//
-//    δ = distance / radius; // angular distance in radians
-//
-//    Δφ = δ * math.Cos(θ);
-//let φ2 = φ1 + Δφ;
-//
-//// check for some daft bugger going past the pole, normalise latitude if so
-//if (Math.abs(φ2) > π / 2) φ2 = φ2 > 0 ? π - φ2 : -π - φ2;
-//
-//    Δψ = Math.log(math.Tan(φ2 / 2 + π / 4) / math.Tan(φ1 / 2 + π / 4));
-//    q = Math.abs(Δψ) > 10e-12 ? Δφ / Δψ : math.Cos(φ1); // E-W course becomes ill-conditioned with 0/0
-//
-//    Δλ = δ * math.Sin(θ) / q;
-//    λ2 = λ1 + Δλ;
-//
-//    lat = φ2* toDegrees;
-//    lon = λ2* toDegrees;
-//
-//return new LatLon(lat, lon);
-//}
-//
-//
 ///**
 // * Returns the loxodromic midpoint (along a rhumb line) between ‘this’ point and second point.
 // *
@@ -606,6 +1536,70 @@ func Intersection(p1 LatLon, brng1 float64, p2 LatLon, brng2 float64) (LatLon, b
 //}
 
 
+/* Gnomonic - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - */
+
+
+/**
+ * Projects a point onto the plane tangent to the earth at ‘this’ point, using the gnomonic
+ * projection (projecting from the earth's centre). Great circles project to straight lines.
+ *
+ * Only the hemisphere facing the tangent point can be represented: points 90° or more from it
+ * have no projection, and NaN is returned for both coordinates. Distortion grows rapidly towards
+ * the edge of that hemisphere.
+ *
+ * @param   {LatLon} point - Point to be projected.
+ * @returns {number} x - Distance east of the tangent point on the projection plane, in metres.
+ * @returns {number} y - Distance north of the tangent point on the projection plane, in metres.
+ *
+ * @example
+ *   const centre = new LatLon(52.205, 0.119);
+ *   const [ x, y ] = centre.gnomonic(new LatLon(48.857, 2.351)); // 163.6 km, -370.3 km
+ */
+func (ll LatLon) Gnomonic(point LatLon) (x, y float64) {
+    φ0 := ll.Lat * toRadians
+    φ := point.Lat * toRadians
+    Δλ := (point.Lon - ll.Lon) * toRadians
+
+    cosc := math.Sin(φ0)*math.Sin(φ) + math.Cos(φ0)*math.Cos(φ)*math.Cos(Δλ) // c = angular distance
+    if cosc < 1e-12 {
+        return math.NaN(), math.NaN() // not in the hemisphere facing the tangent point (allowing for rounding at 90°)
+    }
+
+    x = earthRadius * math.Cos(φ) * math.Sin(Δλ) / cosc
+    y = earthRadius * (math.Cos(φ0)*math.Sin(φ) - math.Sin(φ0)*math.Cos(φ)*math.Cos(Δλ)) / cosc
+
+    return x, y
+}
+
+
+/**
+ * Returns the point whose gnomonic projection about ‘this’ tangent point is (x, y); the inverse of
+ * Gnomonic.
+ *
+ * @param   {number} x - Distance east of the tangent point on the projection plane, in metres.
+ * @param   {number} y - Distance north of the tangent point on the projection plane, in metres.
+ * @returns {LatLon} Point on the earth.
+ *
+ * @example
+ *   const centre = new LatLon(52.205, 0.119);
+ *   const p = centre.gnomonicInverse(163.6e3, -370.3e3); // 48.857°N, 002.351°E
+ */
+func (ll LatLon) GnomonicInverse(x, y float64) LatLon {
+    ρ := math.Hypot(x, y)
+    if ρ == 0 {
+        return ll
+    }
+
+    φ0 := ll.Lat * toRadians
+    c := math.Atan(ρ / earthRadius)
+
+    φ := math.Asin(math.Cos(c)*math.Sin(φ0) + y*math.Sin(c)*math.Cos(φ0)/ρ)
+    Δλ := math.Atan2(x*math.Sin(c), ρ*math.Cos(φ0)*math.Cos(c)-y*math.Sin(φ0)*math.Sin(c))
+
+    return LatLon{Lat: φ * toDegrees, Lon: Wrap180(ll.Lon + Δλ*toDegrees)}
+}
+
+
 /* Area - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - - */
 
 
@@ -622,6 +1616,22 @@ func Intersection(p1 LatLon, brng1 float64, p2 LatLon, brng2 float64) (LatLon, b
  *   const area = LatLon.areaOf(polygon); // 6.18e9 m²
  */
 func AreaOf(polygon []LatLon) float64 {
+    return math.Abs(SignedAreaOf(polygon))
+}
+
+/**
+ * Calculates the signed area of a spherical polygon where the sides of the polygon are great
+ * circle arcs joining the vertices: the area is as given by AreaOf, positive if the vertices run
+ * counter-clockwise (seen from above the surface) and negative if they run clockwise.
+ *
+ * @param   {LatLon[]} polygon - Array of points defining vertices of the polygon.
+ * @returns {number}   The signed area of the polygon in square metres.
+ *
+ * @example
+ *   const polygon = [new LatLon(0,0), new LatLon(0,1), new LatLon(1,0)];
+ *   const area = LatLon.signedAreaOf(polygon); // 6.18e9 m²
+ */
+func SignedAreaOf(polygon []LatLon) float64 {
     // uses method due to Karney: osgeo-org.1560.x6.nabble.com/Area-of-a-spherical-polygon-td3841625.html;
     // for each edge of the polygon, tan(E/2) = tan(Δλ/2)·(tan(φ₁/2)+tan(φ₂/2)) / (1+tan(φ₁/2)·tan(φ₂/2))
     // where E is the spherical excess of the trapezium obtained by extending the edge to the equator
@@ -629,12 +1639,12 @@ func AreaOf(polygon []LatLon) float64 {
 
     const R = earthRadius
 
-    // close polygon so that last point equals first point
-    closed := polygon[0] == polygon[len(polygon)-1]
-    if !closed {
-        polygon = append(polygon, polygon[0])
-    }
+    // remove duplicate vertices, and close polygon so that last point equals first point
+    polygon = closedRing(polygon)
     nVertices := len(polygon) - 1
+    if nVertices < 3 {
+        return 0 // degenerate polygon
+    }
 
     var S float64 // spherical excess in steradians
     for v := 0; v < nVertices; v++ {
@@ -645,23 +1655,182 @@ func AreaOf(polygon []LatLon) float64 {
         S += E
     }
 
+    // S is positive for clockwise polygons; one enclosing a pole picks up an extra ±2π
     if isPoleEnclosedBy(polygon) {
-        S = math.Abs(S) - 2*π
+        S -= math.Copysign(2*π, S)
     }
 
-    A := math.Abs(S * R * R) // area in units of R
+    A := -S * R * R // area in units of R, positive counter-clockwise
+
+    return A
+}
+
+/**
+ * Returns the winding order of a spherical polygon, from the sign of its spherical excess.
+ *
+ * @param   {LatLon[]} polygon - Array of points defining vertices of the polygon.
+ * @returns {number}   +1 if the vertices run counter-clockwise, -1 if they run clockwise, or 0 for
+ *   a degenerate polygon with no area.
+ *
+ * @example
+ *   const polygon = [new LatLon(1,1), new LatLon(1,2), new LatLon(2,2), new LatLon(2,1)];
+ *   const orientation = LatLon.orientation(polygon); // +1
+ */
+func Orientation(polygon []LatLon) int {
+    A := SignedAreaOf(polygon)
+    switch {
+    case A > 0:
+        return +1
+    case A < 0:
+        return -1
+    default:
+        return 0
+    }
+}
 
-    if !closed {
-        polygon = polygon[:len(polygon)-1]
+/**
+ * Calculates the area of a spherical polygon with holes, where the sides of the polygon are great
+ * circle arcs joining the vertices: the area of the outer ring less the areas of the holes.
+ *
+ * Holes are expected to lie within the outer ring, and not to overlap each other; any hole with a
+ * vertex outside the outer ring is ignored.
+ *
+ * @param   {LatLon[]}   outer - Array of points defining vertices of the outer ring.
+ * @param   {LatLon[][]} holes - Arrays of points defining vertices of each hole.
+ * @returns {number}     The area of the polygon in square metres.
+ *
+ * @example
+ *   const outer = [new LatLon(0,0), new LatLon(0,3), new LatLon(3,3), new LatLon(3,0)];
+ *   const hole = [new LatLon(1,1), new LatLon(1,2), new LatLon(2,2), new LatLon(2,1)];
+ *   const area = LatLon.areaOfRings(outer, [hole]); // 9.89e10 m²
+ */
+func AreaOfRings(outer []LatLon, holes [][]LatLon) float64 {
+    A := AreaOf(outer)
+
+    for _, hole := range holes {
+        inside := true
+        for _, p := range hole {
+            if !p.IsEnclosedBy(outer) {
+                inside = false
+                break
+            }
+        }
+        if inside {
+            A -= AreaOf(hole)
+        }
     }
 
     return A
 }
 
+/**
+ * Returns a copy of a polygon in canonical form: closed so that the last point is identical to the
+ * first, with consecutive duplicate vertices removed (as for AreaOf), and with counter-clockwise
+ * winding, so that SignedAreaOf is positive. Clockwise polygons are reversed; degenerate polygons
+ * with no area are just closed.
+ *
+ * @param   {LatLon[]} polygon - Array of points defining vertices of the polygon.
+ * @returns {LatLon[]} Closed, counter-clockwise polygon.
+ *
+ * @example
+ *   const polygon = [new LatLon(1,1), new LatLon(2,1), new LatLon(2,2), new LatLon(1,2)];
+ *   const ccw = LatLon.normalizePolygon(polygon); // 1,1 1,2 2,2 2,1 1,1
+ */
+func NormalizePolygon(polygon []LatLon) []LatLon {
+    ring := closedRing(polygon)
+
+    if Orientation(ring) < 0 {
+        for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+            ring[i], ring[j] = ring[j], ring[i]
+        }
+    }
+
+    return ring
+}
+
+/**
+ * Returns a copy of a polygon with intermediate vertices inserted along its great circle edges
+ * (using IntermediatePointTo), evenly spaced so that no edge is longer than maxEdgeMetres. The
+ * result is closed (as for NormalizePolygon), including the edge from the last vertex back to the
+ * first.
+ *
+ * As the new vertices lie on the original edges, AreaOf is unchanged (it already treats edges as
+ * great circle arcs); densifying is useful before handing a polygon to calculations that treat
+ * edges as straight lines, such as projecting it onto the OS grid or a map.
+ *
+ * @param   {LatLon[]} polygon - Array of points defining vertices of the polygon.
+ * @param   {number}   maxEdgeMetres - Maximum length of any edge; if not positive, no vertices
+ *   are inserted.
+ * @returns {LatLon[]} Closed, densified polygon.
+ *
+ * @example
+ *   const polygon = [new LatLon(0,0), new LatLon(0,1), new LatLon(1,0)];
+ *   const dense = LatLon.densify(polygon, 50e3); // 11 vertices: 10 edges of 37–39km
+ */
+func Densify(polygon []LatLon, maxEdgeMetres float64) []LatLon {
+    ring := closedRing(polygon)
+    if maxEdgeMetres <= 0 || len(ring) < 2 {
+        return ring
+    }
+
+    dense := make([]LatLon, 0, len(ring))
+    for v := 0; v < len(ring)-1; v++ {
+        p1, p2 := ring[v], ring[v+1]
+        dense = append(dense, p1)
+        n := math.Ceil(p1.DistanceTo(p2) / maxEdgeMetres)
+        for i := 1.0; i < n; i++ {
+            dense = append(dense, p1.IntermediatePointTo(p2, i/n))
+        }
+    }
+    dense = append(dense, ring[len(ring)-1])
+
+    return dense
+}
+
+// returns a copy of polygon with consecutive duplicate vertices (as determined by Equals)
+// removed, and closed so that the last point is identical to the first
+func closedRing(polygon []LatLon) []LatLon {
+    ring := make([]LatLon, 0, len(polygon)+1)
+    for _, p := range polygon {
+        if len(ring) == 0 || !ring[len(ring)-1].Equals(p) {
+            ring = append(ring, p)
+        }
+    }
+    if len(ring) == 0 {
+        return ring
+    }
+
+    if len(ring) > 1 && ring[len(ring)-1].Equals(ring[0]) {
+        ring[len(ring)-1] = ring[0]
+    } else {
+        ring = append(ring, ring[0])
+    }
+
+    return ring
+}
+
 // returns whether polygon encloses pole: sum of course deltas around pole is 0° rather than
 // normal ±360°: blog.element84.com/determining-if-a-spherical-polygon-contains-a-pole.html
 func isPoleEnclosedBy(p []LatLon) bool {
     // TODO: any better test than this?
+
+    // drop repeated vertices (such as successive vertices at a pole), which have no bearing
+    var q []LatLon
+    for _, v := range p {
+        if len(q) == 0 || !q[len(q)-1].coincident(v) {
+            q = append(q, v)
+        }
+    }
+    if len(q) < 3 {
+        return false
+    }
+    if q[len(q)-1].coincident(q[0]) {
+        q[len(q)-1] = q[0]
+    } else {
+        q = append(q, q[0])
+    }
+    p = q
+
     ΣΔ := 0.0
     prevBrng := p[0].InitialBearingTo(p[1])
     for v := 0; v < len(p)-1; v++ {
@@ -684,6 +1853,159 @@ func isPoleEnclosedBy(p []LatLon) bool {
 
 
 
+/**
+ * Checks if another point is equal to ‘this’ point, allowing for floating-point rounding: points
+ * are equal if their latitudes and longitudes each differ by no more than 1e-12 degrees (about
+ * 0.1µm).
+ *
+ * @param   {LatLon} point - Point to be compared against this point.
+ * @returns {bool}   True if points have equal latitude and longitude values.
+ *
+ * @example
+ *   const p1 = new LatLon(52.205, 0.119);
+ *   const p2 = new LatLon(52.205, 0.119);
+ *   const equal = p1.equals(p2); // true
+ */
+func (ll LatLon) Equals(point LatLon) bool {
+    const ε = 1e-12
+
+    if math.Abs(ll.Lat-point.Lat) > ε {
+        return false
+    }
+    if math.Abs(ll.Lon-point.Lon) > ε {
+        return false
+    }
+
+    return true
+}
+
+
+// coincident reports whether ll and point are the same location; any two points at the same pole
+// are coincident whatever their longitudes, as are longitudes a whole turn apart (e.g. ±180°).
+func (ll LatLon) coincident(point LatLon) bool {
+    if ll == point || (ll.Lat >= 90 && point.Lat >= 90) || (ll.Lat <= -90 && point.Lat <= -90) {
+        return true
+    }
+    return ll.Lat == point.Lat && Wrap180Canonical(ll.Lon) == Wrap180Canonical(point.Lon)
+}
+
+
+/**
+ * Reports whether ‘this’ point sorts before the supplied point: points are ordered by latitude,
+ * then by longitude. This gives a total ordering, useful for reproducible output.
+ *
+ * @param   {LatLon}  point - Point to be compared against this point.
+ * @returns {boolean} True if this point sorts before point.
+ *
+ * @example
+ *   const p1 = new LatLon(52.205, 0.119);
+ *   const p2 = new LatLon(52.205, 0.120);
+ *   const less = p1.less(p2); // true
+ */
+func (ll LatLon) Less(point LatLon) bool {
+    if ll.Lat != point.Lat {
+        return ll.Lat < point.Lat
+    }
+    return ll.Lon < point.Lon
+}
+
+
+/**
+ * Sorts points in place into the order defined by Less.
+ *
+ * @param {LatLon[]} points - Points to be sorted.
+ */
+func SortPoints(points []LatLon) {
+    sort.Slice(points, func(i, j int) bool {
+        return points[i].Less(points[j])
+    })
+}
+
+
+/**
+ * Returns the components of ‘this’ point in degrees, minutes and seconds, for custom rendering.
+ * Each of latitude and longitude is given as its magnitude in whole degrees, whole minutes and
+ * seconds, and its hemisphere: 'N' or 'S' for latitude, 'E' or 'W' for longitude. Seconds are
+ * rounded to the microsecond, carrying into minutes and degrees when they round to 60.
+ *
+ * @returns {number} latD, latM, latS - Latitude degrees, minutes and seconds.
+ * @returns {byte}   latHem - Latitude hemisphere, 'N' or 'S'.
+ * @returns {number} lonD, lonM, lonS - Longitude degrees, minutes and seconds.
+ * @returns {byte}   lonHem - Longitude hemisphere, 'E' or 'W'.
+ *
+ * @example
+ *   const greenwich = new LatLon(51.47788, -0.00147);
+ *   greenwich.dmsParts(); // 51, 28, 40.368, 'N', 0, 0, 5.292, 'W'
+ */
+func (ll LatLon) DMSParts() (latD, latM int, latS float64, latHem byte, lonD, lonM int, lonS float64, lonHem byte) {
+    lat, lon := Wrap90(ll.Lat), Wrap180(ll.Lon)
+
+    latHem, lonHem = 'N', 'E'
+    if lat < 0 {
+        latHem = 'S'
+    }
+    if lon < 0 {
+        lonHem = 'W'
+    }
+
+    latD, latM, latS = dmsParts(lat)
+    lonD, lonM, lonS = dmsParts(lon)
+
+    return latD, latM, latS, latHem, lonD, lonM, lonS, lonHem
+}
+
+
+/**
+ * Options for formatting a point with FormatWith.
+ *
+ * Mode is 'd' (degrees, the default), 'dm' (degrees+minutes), 'dms' (degrees+minutes+seconds), or
+ * 'n' (signed numeric degrees). DP is the number of decimal places; a negative DP gives the default
+ * for the mode: 4 for d and n, 2 for dm, 0 for dms. Separator is placed between latitude and
+ * longitude (default ', '). UseSymbols marks degrees, minutes and seconds with °, ′ and ″;
+ * otherwise they are separated by spaces. Except in numeric mode, latitudes are padded to 2 digits
+ * of degrees and longitudes to 3, and followed by their hemisphere letter.
+ */
+type FormatOptions struct {
+    Mode       string
+    DP         int
+    Separator  string
+    UseSymbols bool
+}
+
+
+/**
+ * Returns a string representation of ‘this’ point formatted according to the given options.
+ *
+ * @param   {FormatOptions} opts - Formatting options.
+ * @returns {string} Formatted latitude/longitude.
+ *
+ * @example
+ *   const greenwich = new LatLon(51.47788, -0.00147);
+ *   greenwich.formatWith({ Mode: 'dms', DP: 2, UseSymbols: true }); // 51°28′40.37″N, 000°00′05.29″W
+ *   greenwich.formatWith({ Mode: 'n', DP: 4, Separator: '\t' });    // 51.4779	-0.0015
+ */
+func (ll LatLon) FormatWith(opts FormatOptions) string {
+    mode := opts.Mode
+    if mode != "dm" && mode != "dms" && mode != "n" {
+        mode = "d"
+    }
+    dp := opts.DP
+    if dp < 0 {
+        dp = map[string]int{"d": 4, "n": 4, "dm": 2, "dms": 0}[mode]
+    }
+    sep := opts.Separator
+    if sep == "" {
+        sep = ", "
+    }
+
+    if mode == "n" {
+        return strconv.FormatFloat(ll.Lat, 'f', dp, 64) + sep + strconv.FormatFloat(ll.Lon, 'f', dp, 64)
+    }
+
+    return toLatDMS(ll.Lat, mode, dp, opts.UseSymbols) + sep + toLonDMS(ll.Lon, mode, dp, opts.UseSymbols)
+}
+
+
 /**
  * Returns a string representation of ‘this’ point, formatted as degrees, degrees+minutes, or
  * degrees+minutes+seconds.
@@ -2,6 +2,8 @@ package osgridref
 
 import (
 	"github.com/stretchr/testify/require"
+	"math"
+	"math/rand"
 	"strconv"
 	"strings"
 	"testing"
@@ -38,6 +40,219 @@ func TestLatLon_DistanceTo(t *testing.T) {
 	}
 }
 
+func TestUnitConversions(t *testing.T) {
+	assert.Equal(t, 1.0, 1000*MetresToKm)
+	assert.InDelta(t, 0.621371192237334, 1000*MetresToMiles, 1e-15)
+	assert.InDelta(t, 0.5399568034557236, 1000*MetresToNauticalMiles, 1e-15)
+	assert.Equal(t, 1.0, 1609.344*MetresToMiles)
+	assert.Equal(t, 1.0, 1852*MetresToNauticalMiles)
+
+	// Cambridge to Paris: 404.3 km, 251.2 miles
+	assert.InDelta(t, 404.3, cambridge.DistanceTo(paris)*MetresToKm, 0.05)
+	assert.InDelta(t, 251.2, cambridge.DistanceTo(paris)*MetresToMiles, 0.05)
+}
+
+func TestLatLon_DistanceToLawOfCosines(t *testing.T) {
+	// Over medium distances the law of cosines agrees with haversine.
+	pairs := [][2]LatLon{
+		{cambridge, paris},
+		{stansted, cdg},
+		{greenwich, bxl},
+		{valley, caernafon},
+		{LatLon{Lat: 0, Lon: 0}, LatLon{Lat: 10, Lon: 20}},
+		{LatLon{Lat: -33.8568, Lon: 151.2153}, LatLon{Lat: -37.8136, Lon: 144.9631}},
+	}
+	for _, p := range pairs {
+		assert.InEpsilon(t, p[0].DistanceTo(p[1]), p[0].DistanceToLawOfCosines(p[1]), 1e-9, "%v to %v", p[0], p[1])
+	}
+
+	// Coincident and antipodal points are within acos's domain.
+	assert.Equal(t, 0.0, cambridge.DistanceToLawOfCosines(cambridge))
+	assert.InDelta(t, π*earthRadius, LatLon{Lat: 10, Lon: 20}.DistanceToLawOfCosines(LatLon{Lat: -10, Lon: -160}), 1e-3)
+
+	// Over very short distances it loses precision: haversine gives 1.1cm here, but the law of
+	// cosines is out by well over 1mm.
+	short := LatLon{Lat: cambridge.Lat + 1e-7, Lon: cambridge.Lon}
+	assert.InDelta(t, 0.0111, cambridge.DistanceTo(short), 0.0001)
+	assert.Greater(t, math.Abs(cambridge.DistanceTo(short)-cambridge.DistanceToLawOfCosines(short)), 0.001)
+}
+
+func TestLatLon_DistanceToChecked(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to LatLon
+		wantErr  bool
+	}{
+		{name: "valid", from: cambridge, to: paris},
+		{name: "extremes", from: LatLon{Lat: -90, Lon: -180}, to: LatLon{Lat: 90, Lon: 180}},
+		{name: "NaN latitude", from: LatLon{Lat: math.NaN(), Lon: 0}, to: paris, wantErr: true},
+		{name: "NaN longitude", from: cambridge, to: LatLon{Lat: 0, Lon: math.NaN()}, wantErr: true},
+		{name: "infinite longitude", from: cambridge, to: LatLon{Lat: 0, Lon: math.Inf(1)}, wantErr: true},
+		{name: "lon out of range", from: cambridge, to: LatLon{Lat: 0, Lon: 200}, wantErr: true},
+		{name: "lat out of range", from: LatLon{Lat: -91, Lon: 0}, to: paris, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.from.DistanceToChecked(tt.to)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.from.DistanceTo(tt.to), got)
+		})
+	}
+}
+
+func TestLatLon_Normalized(t *testing.T) {
+	tests := []struct {
+		name string
+		ll   LatLon
+		want LatLon
+	}{
+		{name: "in range", ll: cambridge, want: cambridge},
+		{name: "past north pole", ll: LatLon{Lat: 95, Lon: 10}, want: LatLon{Lat: 85, Lon: 10}},
+		{name: "past south pole", ll: LatLon{Lat: -95, Lon: 10}, want: LatLon{Lat: -85, Lon: 10}},
+		{name: "past antimeridian", ll: LatLon{Lat: 10, Lon: 190}, want: LatLon{Lat: 10, Lon: -170}},
+		{name: "both", ll: LatLon{Lat: 95, Lon: 190}, want: LatLon{Lat: 85, Lon: -170}},
+		{name: "poles", ll: LatLon{Lat: 90, Lon: -180}, want: LatLon{Lat: 90, Lon: -180}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.ll.Normalized()
+			assert.InDelta(t, tt.want.Lat, got.Lat, 1e-12)
+			assert.InDelta(t, tt.want.Lon, got.Lon, 1e-12)
+			assert.NoError(t, got.check())
+		})
+	}
+}
+
+func TestLatLon_IsWithinDistance(t *testing.T) {
+	assert.True(t, cambridge.IsWithinDistance(paris, 405e3))
+	assert.False(t, cambridge.IsWithinDistance(paris, 404e3))
+	assert.True(t, paris.IsWithinDistance(cambridge, 405e3))
+	assert.True(t, cambridge.IsWithinDistance(cambridge, 0))
+}
+
+func TestLatLon_ApproxDistanceTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to LatLon
+	}{
+		{name: "self", from: cambridge, to: cambridge},
+		{name: "valley-to-caernafon", from: valley, to: caernafon},
+		{name: "100km north", from: cambridge, to: cambridge.DestinationPoint(100e3, 0)},
+		{name: "100km east", from: cambridge, to: cambridge.DestinationPoint(100e3, 90)},
+		{name: "100km south-west", from: greenwich, to: greenwich.DestinationPoint(100e3, 225)},
+		{name: "100km anti-meridian", from: LatLon{Lat: 52, Lon: 179.5}, to: LatLon{Lat: 52, Lon: 179.5}.DestinationPoint(100e3, 80)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.from.DistanceTo(tt.to)
+			got := tt.from.ApproxDistanceTo(tt.to)
+			assert.InDelta(t, want, got, want*0.01)
+		})
+	}
+}
+
+func BenchmarkLatLon_DistanceTo(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = cambridge.DistanceTo(paris)
+	}
+}
+
+func BenchmarkLatLon_ApproxDistanceTo(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = cambridge.ApproxDistanceTo(paris)
+	}
+}
+
+func TestOrigin_DistanceTo(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, from := range []LatLon{cambridge, paris, {Lat: -90, Lon: 0}, {Lat: 0, Lon: 180}} {
+		origin := from.AsOrigin()
+		for i := 0; i < 1000; i++ {
+			to := LatLon{Lat: r.Float64()*180 - 90, Lon: r.Float64()*360 - 180}
+			require.Equal(t, from.DistanceTo(to), origin.DistanceTo(to), "%v to %v", from, to)
+		}
+		assert.Equal(t, 0.0, origin.DistanceTo(from))
+	}
+}
+
+func distanceTargets(n int) []LatLon {
+	r := rand.New(rand.NewSource(1))
+	targets := make([]LatLon, n)
+	for i := range targets {
+		targets[i] = LatLon{Lat: r.Float64()*180 - 90, Lon: r.Float64()*360 - 180}
+	}
+	return targets
+}
+
+func BenchmarkLatLon_DistanceTo_100k(b *testing.B) {
+	targets := distanceTargets(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p := range targets {
+			_ = cambridge.DistanceTo(p)
+		}
+	}
+}
+
+func BenchmarkOrigin_DistanceTo_100k(b *testing.B) {
+	targets := distanceTargets(100_000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		origin := cambridge.AsOrigin()
+		for _, p := range targets {
+			_ = origin.DistanceTo(p)
+		}
+	}
+}
+
+func TestLatLon_Nearest(t *testing.T) {
+	candidates := []LatLon{paris, greenwich, valley, {Lat: -1, Lon: -179}}
+	tests := []struct {
+		name      string
+		from      LatLon
+		want      int
+		wantPoint LatLon
+	}{
+		{name: "cambridge", from: cambridge, want: 1, wantPoint: greenwich},
+		{name: "caernafon", from: caernafon, want: 2, wantPoint: valley},
+		{name: "bxl", from: bxl, want: 0, wantPoint: paris},
+		{name: "date line", from: LatLon{Lat: 0, Lon: 179}, want: 3, wantPoint: LatLon{Lat: -1, Lon: -179}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, i, d := tt.from.Nearest(candidates)
+			assert.Equal(t, tt.want, i)
+			assert.Equal(t, tt.wantPoint, got)
+			assert.Equal(t, tt.from.DistanceTo(tt.wantPoint), d)
+		})
+	}
+
+	got, i, d := cambridge.Nearest(nil)
+	assert.Equal(t, LatLon{}, got)
+	assert.Equal(t, -1, i)
+	assert.True(t, math.IsInf(d, 1))
+}
+
+func TestUniquePoints(t *testing.T) {
+	fixes := []LatLon{
+		cambridge,
+		cambridge.DestinationPoint(1.5, 40),
+		paris,
+		cambridge.DestinationPoint(2, 200),
+		paris.DestinationPoint(10, 0),
+	}
+
+	assert.Equal(t, []LatLon{cambridge, paris, fixes[4]}, UniquePoints(fixes, 5))
+	assert.Equal(t, fixes, UniquePoints(fixes, 1))
+	assert.Equal(t, []LatLon{cambridge, paris}, UniquePoints(fixes, 20))
+	assert.Equal(t, []LatLon{cambridge}, UniquePoints([]LatLon{cambridge, cambridge, cambridge}, 0))
+	assert.Empty(t, UniquePoints(nil, 5))
+}
+
 func TestLatLon_BearingTo(t *testing.T) {
 	justNorthOfCambridge := LatLon{Lat: 52.206, Lon: 0.119}
 	justWestOfCambridge := LatLon{Lat: 52.205, Lon: 0.118}
@@ -51,21 +266,149 @@ func TestLatLon_BearingTo(t *testing.T) {
 		{name: "justNorthOfCambridge", from: cambridge, to: justNorthOfCambridge, init: 0, final: 0},
 		{name: "justWestOfCambridge", from: cambridge, to: justWestOfCambridge, init: 270, final: 270},
 		{name: "paris", from: cambridge, to: paris, init: 156.2, final: 157.9},
-		{name: "north pole", from: cambridge, to: LatLon{Lat: 90, Lon: 0}, init: 0, final: 360},
+		{name: "north pole", from: cambridge, to: LatLon{Lat: 90, Lon: 0}, init: 0, final: 0},
 		{name: "south pole", from: cambridge, to: LatLon{Lat: -90, Lon: 0}, init: 180, final: 180},
 		{name: "pole-to-pole", from: LatLon{Lat: 90, Lon: 0}, to: LatLon{Lat: -90, Lon: 0}, init: 180, final: 180},
+		{name: "from north pole", from: LatLon{Lat: 90, Lon: 0}, to: cambridge, init: 180, final: 180},
+		{name: "from north pole east", from: LatLon{Lat: 90, Lon: 0}, to: LatLon{Lat: 10, Lon: 90}, init: 180, final: 180},
+		{name: "from north pole west", from: LatLon{Lat: 90, Lon: 45}, to: LatLon{Lat: -10, Lon: -120}, init: 180, final: 180},
+		{name: "from south pole", from: LatLon{Lat: -90, Lon: 0}, to: cambridge, init: 0, final: 0},
+		{name: "from south pole west", from: LatLon{Lat: -90, Lon: 0}, to: LatLon{Lat: 10, Lon: -90}, init: 0, final: 0},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			init := tt.from.InitialBearingTo(tt.to)
 			final := tt.from.FinalBearingTo(tt.to)
+			assert.False(t, math.IsNaN(init))
+			assert.False(t, math.IsNaN(final))
 			assert.InDelta(t, tt.init, init, .5)
 			assert.InDelta(t, tt.final, final, .5)
 		})
 	}
 }
 
+func TestLatLon_BearingTo_Poles(t *testing.T) {
+	northPole := LatLon{Lat: 90, Lon: 0}
+	southPole := LatLon{Lat: -90, Lon: 0}
+
+	tests := []struct {
+		name     string
+		from, to LatLon
+		init     float64
+		final    float64
+	}{
+		{name: "north pole origin", from: northPole, to: cambridge, init: 180, final: 180},
+		{name: "north pole origin, other longitude", from: LatLon{Lat: 90, Lon: 123}, to: LatLon{Lat: -30, Lon: -60}, init: 180, final: 180},
+		{name: "south pole origin", from: southPole, to: cambridge, init: 0, final: 0},
+		{name: "north pole destination", from: cambridge, to: northPole, init: 0, final: 0},
+		{name: "north pole destination, other longitude", from: paris, to: LatLon{Lat: 90, Lon: -77}, init: 0, final: 0},
+		{name: "south pole destination", from: cambridge, to: southPole, init: 180, final: 180},
+		{name: "south pole destination, other longitude", from: LatLon{Lat: -33.9, Lon: 151.2}, to: LatLon{Lat: -90, Lon: 45}, init: 180, final: 180},
+		{name: "pole to pole", from: northPole, to: southPole, init: 180, final: 180},
+		{name: "south pole to north pole", from: southPole, to: northPole, init: 0, final: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.init, tt.from.InitialBearingTo(tt.to))
+			assert.Equal(t, tt.final, tt.from.FinalBearingTo(tt.to))
+		})
+	}
+}
+
+func TestLatLon_BearingTo_Coincident(t *testing.T) {
+	// the bearing between coincident points is undefined
+	for _, p := range [][2]LatLon{
+		{cambridge, cambridge},
+		{{Lat: 90, Lon: 0}, {Lat: 90, Lon: 100}},
+		{{Lat: -90, Lon: 0}, {Lat: -90, Lon: -45}},
+	} {
+		assert.True(t, math.IsNaN(p[0].InitialBearingTo(p[1])), "initial bearing %v to %v", p[0], p[1])
+		assert.True(t, math.IsNaN(p[0].FinalBearingTo(p[1])), "final bearing %v to %v", p[0], p[1])
+	}
+
+	assert.True(t, math.IsNaN(cambridge.TurnAngle(cambridge, paris)))
+	assert.True(t, math.IsNaN(paris.CrossTrackDistanceTo(cambridge, cambridge)))
+	assert.Equal(t, 0.0, cambridge.CrossTrackDistanceTo(cambridge, paris))
+
+	_, bearing := cambridge.DestinationPointAndBearing(0, 45)
+	assert.Equal(t, 45.0, bearing)
+}
+
+func TestLatLon_TurnAngle(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to LatLon
+		want     float64
+	}{
+		{name: "straight ahead", from: cambridge.DestinationPoint(1000, 30), to: cambridge.DestinationPoint(5000, 30), want: 0},
+		{name: "hard right", from: cambridge.DestinationPoint(1000, 0), to: cambridge.DestinationPoint(1000, 90), want: 90},
+		{name: "hard left", from: cambridge.DestinationPoint(1000, 0), to: cambridge.DestinationPoint(1000, 270), want: -90},
+		{name: "right across north", from: cambridge.DestinationPoint(1000, 350), to: cambridge.DestinationPoint(1000, 10), want: 20},
+		{name: "left across north", from: cambridge.DestinationPoint(1000, 10), to: cambridge.DestinationPoint(1000, 350), want: -20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cambridge.TurnAngle(tt.from, tt.to)
+			assert.InDelta(t, tt.want, got, 0.01)
+		})
+	}
+}
+
+func TestLatLon_Fan(t *testing.T) {
+	tests := []struct {
+		name                     string
+		bearingStart, bearingEnd float64
+		count                    int
+		wantBearings             []float64
+	}{
+		{name: "eastern semicircle", bearingStart: 0, bearingEnd: 180, count: 5, wantBearings: []float64{0, 45, 90, 135, 180}},
+		{name: "northern semicircle", bearingStart: 270, bearingEnd: 90, count: 7, wantBearings: []float64{270, 300, 330, 0, 30, 60, 90}},
+		{name: "single point", bearingStart: 45, bearingEnd: 90, count: 1, wantBearings: []float64{45}},
+		{name: "none", bearingStart: 45, bearingEnd: 90, count: 0, wantBearings: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cambridge.Fan(tt.bearingStart, tt.bearingEnd, tt.count, 1000)
+			require.Len(t, got, len(tt.wantBearings))
+			for i, p := range got {
+				assert.InDelta(t, 1000, cambridge.DistanceTo(p), 1e-6)
+				assert.InDelta(t, 0, Wrap180(tt.wantBearings[i]-cambridge.InitialBearingTo(p)), 1e-6, "point %d", i)
+			}
+		})
+	}
+}
+
+func TestLatLon_IsInSector(t *testing.T) {
+	tests := []struct {
+		name                     string
+		bearing                  float64
+		centreBearing, halfAngle float64
+		want                     bool
+	}{
+		{name: "centre", bearing: 90, centreBearing: 90, halfAngle: 15, want: true},
+		{name: "inside", bearing: 100, centreBearing: 90, halfAngle: 15, want: true},
+		{name: "outside", bearing: 110, centreBearing: 90, halfAngle: 15, want: false},
+		{name: "opposite", bearing: 270, centreBearing: 90, halfAngle: 15, want: false},
+		{name: "straddling north, east side", bearing: 5, centreBearing: 350, halfAngle: 20, want: true},
+		{name: "straddling north, west side", bearing: 335, centreBearing: 350, halfAngle: 20, want: true},
+		{name: "straddling north, north", bearing: 0, centreBearing: 350, halfAngle: 20, want: true},
+		{name: "straddling north, beyond east edge", bearing: 15, centreBearing: 350, halfAngle: 20, want: false},
+		{name: "straddling north, beyond west edge", bearing: 325, centreBearing: 350, halfAngle: 20, want: false},
+		{name: "centre given as negative bearing", bearing: 355, centreBearing: -10, halfAngle: 20, want: true},
+		{name: "whole circle", bearing: 180, centreBearing: 0, halfAngle: 180, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			point := cambridge.DestinationPoint(1000, tt.bearing)
+			assert.Equal(t, tt.want, cambridge.IsInSector(point, tt.centreBearing, tt.halfAngle))
+		})
+	}
+
+	assert.True(t, cambridge.IsInSector(cambridge, 90, 1), "coincident point")
+}
+
 func TestLatLon_DestinationPoint(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -76,6 +419,10 @@ func TestLatLon_DestinationPoint(t *testing.T) {
 	}{
 		{name: "no-op", from: cambridge, distance: 0, bearing: 77, want: cambridge},
 		{name: "greenwich", from: greenwich, distance: 7794, bearing: 300.7, want: LatLon{Lat: 51.5136, Lon: -0.0983}},
+		{name: "over north pole", from: LatLon{Lat: 89.9, Lon: 10}, distance: 30000, bearing: 0, want: LatLon{Lat: 89.830204, Lon: -170}},
+		{name: "over north pole, wrapping east", from: LatLon{Lat: 89.9, Lon: 170}, distance: 30000, bearing: 0, want: LatLon{Lat: 89.830204, Lon: -10}},
+		{name: "over south pole", from: LatLon{Lat: -89.9, Lon: -100}, distance: 30000, bearing: 180, want: LatLon{Lat: -89.830204, Lon: 80}},
+		{name: "across antimeridian", from: LatLon{Lat: 0, Lon: 179.9}, distance: 22239, bearing: 90, want: LatLon{Lat: 0, Lon: -179.9}},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -86,6 +433,199 @@ func TestLatLon_DestinationPoint(t *testing.T) {
 	}
 }
 
+func TestLatLon_DestinationPoint_ToPole(t *testing.T) {
+	// Travelling exactly to the pole must not take sinφ2 outside asin's domain.
+	for lat := 80.0; lat < 90; lat += 0.001 {
+		for _, bearing := range []float64{0, 1e-9, 359.9999999} {
+			from := LatLon{Lat: lat, Lon: 10}
+			got := from.DestinationPoint((90-lat)*toRadians*earthRadius, bearing)
+			require.False(t, math.IsNaN(got.Lat) || math.IsNaN(got.Lon), "%v bearing %v gave %v", from, bearing, got)
+			require.InDelta(t, 90, got.Lat, 1e-5)
+			require.True(t, got.Lon >= -180 && got.Lon <= 180)
+		}
+	}
+}
+
+func TestLatLon_DestinationFromRangeBearing(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    LatLon
+		wantErr bool
+	}{
+		{name: "km", s: "045/12.5km", want: LatLon{Lat: 51.5573, Lon: 0.1264}},
+		{name: "nautical miles", s: "270/10nm", want: LatLon{Lat: 51.4776, Lon: -0.2689}},
+		{name: "metres", s: "300.7/7794m", want: LatLon{Lat: 51.5136, Lon: -0.0983}},
+		{name: "default metres", s: "300.7/7794", want: LatLon{Lat: 51.5136, Lon: -0.0983}},
+		{name: "miles, spaced, lower case", s: " 90 / 1 mi ", want: LatLon{Lat: 51.4779, Lon: 0.0218}},
+		{name: "upper case", s: "045/12.5KM", want: LatLon{Lat: 51.5573, Lon: 0.1264}},
+		{name: "empty", s: "", wantErr: true},
+		{name: "no range", s: "045", wantErr: true},
+		{name: "bad unit", s: "045/12.5ft", wantErr: true},
+		{name: "bearing too big", s: "361/10km", wantErr: true},
+		{name: "negative range", s: "045/-10km", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := greenwich.DestinationFromRangeBearing(tt.s)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want.Lat, got.Lat, 5e-5)
+			assert.InDelta(t, tt.want.Lon, got.Lon, 5e-5)
+		})
+	}
+}
+
+func TestLatLon_DestinationPointAndBearing(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     LatLon
+		distance float64
+		bearing  float64
+	}{
+		{name: "no-op", from: cambridge, distance: 0, bearing: 77},
+		{name: "greenwich", from: greenwich, distance: 7794, bearing: 300.7},
+		{name: "long-haul", from: cambridge, distance: 5_000_000, bearing: 45},
+		{name: "southern", from: LatLon{Lat: -33.9, Lon: 151.2}, distance: 2_000_000, bearing: 200},
+		{name: "beyond half circumference", from: cambridge, distance: 30_000_000, bearing: 45},
+		{name: "three-quarters of equator", from: LatLon{Lat: 0, Lon: 0}, distance: 1.5 * π * earthRadius, bearing: 90},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, final := tt.from.DestinationPointAndBearing(tt.distance, tt.bearing)
+			want := tt.from.DestinationPoint(tt.distance, tt.bearing)
+			assert.Equal(t, want, got)
+			if tt.distance == 0 {
+				assert.InDelta(t, tt.bearing, final, 1e-9)
+				return
+			}
+			if tt.distance < π*earthRadius {
+				assert.InDelta(t, tt.from.FinalBearingTo(got), final, 1e-9)
+				// reversing the arrival bearing gives the initial bearing back to the start
+				assert.InDelta(t, got.InitialBearingTo(tt.from), Wrap360(final+180), 1e-9)
+			}
+			// stepping back along the arrival bearing retraces the path travelled
+			behind := got.DestinationPoint(1000, final+180)
+			assert.Less(t, behind.DistanceTo(tt.from.DestinationPoint(tt.distance-1000, tt.bearing)), 1e-3)
+		})
+	}
+
+	// heading east along the equator, the arrival heading is still east
+	_, final := LatLon{Lat: 0, Lon: 0}.DestinationPointAndBearing(1.5*π*earthRadius, 90)
+	assert.InDelta(t, 90, final, 1e-9)
+}
+
+func TestLatLon_MidpointTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to LatLon
+		want     LatLon
+	}{
+		{name: "self", from: cambridge, to: cambridge, want: cambridge},
+		{name: "paris", from: cambridge, to: paris, want: LatLon{Lat: 50.5363, Lon: 1.2746}},
+		{name: "anti-meridian", from: LatLon{Lat: 0, Lon: 179}, to: LatLon{Lat: 0, Lon: -179}, want: LatLon{Lat: 0, Lon: 180}},
+		{name: "anti-meridian reversed", from: LatLon{Lat: 0, Lon: -179}, to: LatLon{Lat: 0, Lon: 179}, want: LatLon{Lat: 0, Lon: 180}},
+		{name: "anti-meridian north", from: LatLon{Lat: 60, Lon: 170}, to: LatLon{Lat: 60, Lon: -170}, want: LatLon{Lat: 60.3783, Lon: 180}},
+		{name: "prime meridian", from: LatLon{Lat: 0, Lon: 1}, to: LatLon{Lat: 0, Lon: -1}, want: LatLon{Lat: 0, Lon: 0}},
+		{name: "near north pole", from: LatLon{Lat: 89.5, Lon: 0}, to: LatLon{Lat: 89.5, Lon: 90}, want: LatLon{Lat: 89.6464, Lon: 45}},
+		{name: "very near north pole", from: LatLon{Lat: 89.99, Lon: -45}, to: LatLon{Lat: 89.99, Lon: 135.5}, want: LatLon{Lat: 89.99996, Lon: -134.75}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.MidpointTo(tt.to)
+			assert.InDelta(t, tt.from.DistanceTo(got), tt.to.DistanceTo(got), 1e-6)
+			assert.InDelta(t, tt.want.Lat, got.Lat, 5e-5)
+			// compare longitudes modulo 360°, so that 180° and -180° are equivalent
+			assert.InDelta(t, 0, Wrap180(got.Lon-tt.want.Lon), 5e-5)
+			assert.True(t, -180 <= got.Lon && got.Lon <= 180, "lon %v out of range", got.Lon)
+		})
+	}
+}
+
+func TestLatLon_IntermediatePointTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to LatLon
+		fraction float64
+		want     LatLon
+	}{
+		{name: "self", from: cambridge, to: cambridge, fraction: 0.5, want: cambridge},
+		{name: "start", from: cambridge, to: paris, fraction: 0, want: cambridge},
+		{name: "end", from: cambridge, to: paris, fraction: 1, want: paris},
+		{name: "quarter", from: cambridge, to: paris, fraction: 0.25, want: LatLon{Lat: 51.3721, Lon: 0.7073}},
+		{name: "half", from: cambridge, to: paris, fraction: 0.5, want: cambridge.MidpointTo(paris)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.IntermediatePointTo(tt.to, tt.fraction)
+			assert.InDelta(t, tt.want.Lat, got.Lat, 5e-5)
+			assert.InDelta(t, tt.want.Lon, got.Lon, 5e-5)
+		})
+	}
+}
+
+func TestLatLon_GreatCircleTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   LatLon
+		maxSegment float64
+		wantPoints int
+	}{
+		{name: "self", from: cambridge, to: cambridge, maxSegment: 1000, wantPoints: 2},
+		{name: "single segment", from: valley, to: caernafon, maxSegment: 50e3, wantPoints: 2},
+		{name: "paris", from: cambridge, to: paris, maxSegment: 50e3, wantPoints: 10},
+		{name: "exact multiple", from: cambridge, to: paris, maxSegment: cambridge.DistanceTo(paris) / 4, wantPoints: 5},
+		{name: "long-haul", from: greenwich, to: LatLon{Lat: 40.6413, Lon: -73.7781}, maxSegment: 100e3, wantPoints: 57},
+		{name: "NaN segment", from: cambridge, to: paris, maxSegment: math.NaN(), wantPoints: 2},
+		{name: "infinite segment", from: cambridge, to: paris, maxSegment: math.Inf(1), wantPoints: 2},
+		{name: "zero segment", from: cambridge, to: paris, maxSegment: 0, wantPoints: 2},
+		{name: "tiny segment", from: LatLon{Lat: 0, Lon: 0}, to: LatLon{Lat: 1, Lon: 1}, maxSegment: 1e-300, wantPoints: maxPathSegments + 1},
+		{name: "subnormal segment", from: cambridge, to: paris, maxSegment: math.SmallestNonzeroFloat64, wantPoints: maxPathSegments + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.GreatCircleTo(tt.to, tt.maxSegment)
+			require.Len(t, got, tt.wantPoints)
+			assert.Equal(t, tt.from, got[0])
+			assert.Equal(t, tt.to, got[len(got)-1])
+			if !(tt.maxSegment > 0) || len(got) > maxPathSegments {
+				return
+			}
+			for i := 1; i < len(got); i++ {
+				assert.LessOrEqual(t, got[i-1].DistanceTo(got[i]), tt.maxSegment+1e-6)
+			}
+		})
+	}
+}
+
+func TestLatLon_OffsetMetres(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        LatLon
+		east, north float64
+		want        LatLon
+	}{
+		{name: "no-op", from: cambridge, want: cambridge},
+		{name: "100m north", from: cambridge, north: 100, want: LatLon{Lat: cambridge.Lat + 0.0009, Lon: cambridge.Lon}},
+		{name: "100m south", from: cambridge, north: -100, want: LatLon{Lat: cambridge.Lat - 0.0009, Lon: cambridge.Lon}},
+		{name: "1km east", from: cambridge, east: 1000, want: cambridge.DestinationPoint(1000, 90)},
+		{name: "1km west", from: cambridge, east: -1000, want: cambridge.DestinationPoint(1000, 270)},
+		{name: "2km north-east", from: greenwich, east: 2000, north: 2000, want: greenwich.DestinationPoint(2000*math.Sqrt2, 45)},
+		{name: "anti-meridian", from: LatLon{Lat: 0, Lon: 179.9999}, east: 100, want: LatLon{Lat: 0, Lon: -179.99920}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.OffsetMetres(tt.east, tt.north)
+			assert.Less(t, got.DistanceTo(tt.want), 1.0)
+		})
+	}
+
+	assert.InDelta(t, 0.0009, cambridge.OffsetMetres(0, 100).Lat-cambridge.Lat, 1e-5)
+}
+
 func TestIntersection(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -109,6 +649,389 @@ func TestIntersection(t *testing.T) {
 	}
 }
 
+func TestLatLon_IsAntipodalTo(t *testing.T) {
+	p := LatLon{Lat: 20, Lon: 30}
+	tests := []struct {
+		name  string
+		other LatLon
+		want  bool
+	}{
+		{name: "exactly antipodal", other: LatLon{Lat: -20, Lon: -150}, want: true},
+		{name: "nearly antipodal", other: LatLon{Lat: -20.0003, Lon: -150}, want: true},    // 33m
+		{name: "not quite antipodal", other: LatLon{Lat: -20.002, Lon: -150}, want: false}, // 222m
+		{name: "same point", other: p, want: false},
+		{name: "across the anti-meridian", other: LatLon{Lat: -20, Lon: 210}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, p.IsAntipodalTo(tt.other))
+			assert.Equal(t, tt.want, tt.other.IsAntipodalTo(p))
+		})
+	}
+
+	assert.True(t, LatLon{Lat: 90, Lon: 0}.IsAntipodalTo(LatLon{Lat: -90, Lon: 123}))
+	assert.True(t, LatLon{Lat: 0, Lon: 180}.IsAntipodalTo(LatLon{Lat: 0, Lon: 0}))
+}
+
+func TestIntersection_Antipodal(t *testing.T) {
+	p1 := LatLon{Lat: 20, Lon: 30}
+	for _, p2 := range []LatLon{{Lat: -20, Lon: -150}, {Lat: -20.0003, Lon: -149.9997}} {
+		for _, brng := range []float64{0, 50, 100, 200} {
+			_, ok := Intersection(p1, 10, p2, brng)
+			assert.False(t, ok, "%v, %v", p2, brng)
+		}
+	}
+
+	// 0.01° away from the antipode, the intersection is well defined again
+	p2 := LatLon{Lat: -20.01, Lon: -150}
+	got, ok := Intersection(p1, 10, p2, 350)
+	require.True(t, ok)
+	assert.InDelta(t, 0, Wrap180(p1.InitialBearingTo(got)-10), 1e-6)
+	assert.InDelta(t, 0, Wrap180(p2.InitialBearingTo(got)-350), 1e-6)
+}
+
+func TestForwardIntersection(t *testing.T) {
+	tests := []struct {
+		name         string
+		brng1, brng2 float64
+		want         LatLon
+		ok           bool
+	}{
+		{name: "both heading towards crossing", brng1: 108.547, brng2: 32.435, want: bxl, ok: true},
+		{name: "both heading away", brng1: 288.547, brng2: 212.435, want: LatLon{Lat: -50.9078, Lon: -175.4916}, ok: true},
+		{name: "crossing behind second", brng1: 108.547, brng2: 212.435},
+		{name: "crossing behind first", brng1: 288.547, brng2: 32.435},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ForwardIntersection(stansted, tt.brng1, cdg, tt.brng2)
+			require.Equal(t, tt.ok, ok)
+			if !ok {
+				return
+			}
+			assert.InDelta(t, tt.want.Lat, got.Lat, 0.0001)
+			assert.InDelta(t, tt.want.Lon, got.Lon, 0.0001)
+
+			// ahead of both start points
+			assert.InDelta(t, 0, Wrap180(stansted.InitialBearingTo(got)-tt.brng1), 1e-6)
+			assert.InDelta(t, 0, Wrap180(cdg.InitialBearingTo(got)-tt.brng2), 1e-6)
+		})
+	}
+
+	// same great circle
+	_, ok := ForwardIntersection(LatLon{Lat: 0, Lon: 0}, 90, LatLon{Lat: 0, Lon: 10}, 90)
+	assert.False(t, ok)
+}
+
+func TestCrossingParallels(t *testing.T) {
+	lon1, lon2, ok := CrossingParallels(LatLon{Lat: 0, Lon: 0}, LatLon{Lat: 60, Lon: 30}, 30)
+	require.True(t, ok)
+	assert.InDelta(t, 9.594, lon1, 0.001)
+	assert.InDelta(t, 170.406, lon2, 0.001)
+
+	_, _, ok = CrossingParallels(LatLon{Lat: 0, Lon: 0}, LatLon{Lat: 60, Lon: 30}, 80)
+	assert.False(t, ok, "latitude not reached")
+	_, _, ok = CrossingParallels(cambridge, cambridge, 30)
+	assert.False(t, ok, "coincident points")
+}
+
+func TestClipSegment(t *testing.T) {
+	sw, ne := LatLon{Lat: 50, Lon: -1}, LatLon{Lat: 53, Lon: 2}
+
+	tests := []struct {
+		name           string
+		start, end     LatLon
+		wantVisible    bool
+		wantP1, wantP2 LatLon
+	}{
+		{name: "inside", start: LatLon{Lat: 51, Lon: 0}, end: LatLon{Lat: 52, Lon: 1}, wantVisible: true,
+			wantP1: LatLon{Lat: 51, Lon: 0}, wantP2: LatLon{Lat: 52, Lon: 1}},
+		{name: "crossing east edge", start: LatLon{Lat: 51, Lon: 0}, end: LatLon{Lat: 51.5, Lon: 5}, wantVisible: true,
+			wantP1: LatLon{Lat: 51, Lon: 0}, wantP2: LatLon{Lat: 51.226911, Lon: 2}},
+		{name: "crossing south and east edges", start: LatLon{Lat: 48, Lon: -3}, end: LatLon{Lat: 54, Lon: 4}, wantVisible: true,
+			wantP1: LatLon{Lat: 50, Lon: -0.913340}, wantP2: LatLon{Lat: 52.481905, Lon: 2}},
+		{name: "bulging into box", start: LatLon{Lat: 49.8, Lon: -10}, end: LatLon{Lat: 49.8, Lon: 10}, wantVisible: true,
+			wantP1: LatLon{Lat: 50.227565, Lon: -1}, wantP2: LatLon{Lat: 50.214689, Lon: 2}},
+		{name: "outside", start: LatLon{Lat: 40, Lon: 0}, end: LatLon{Lat: 41, Lon: 1}},
+		{name: "bulging but outside", start: LatLon{Lat: 49, Lon: -5}, end: LatLon{Lat: 49, Lon: 5}},
+		{name: "point inside", start: LatLon{Lat: 51, Lon: 0}, end: LatLon{Lat: 51, Lon: 0}, wantVisible: true,
+			wantP1: LatLon{Lat: 51, Lon: 0}, wantP2: LatLon{Lat: 51, Lon: 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p1, p2, visible := ClipSegment(tt.start, tt.end, sw, ne)
+			require.Equal(t, tt.wantVisible, visible)
+			if !visible {
+				return
+			}
+			assert.InDelta(t, tt.wantP1.Lat, p1.Lat, 1e-6)
+			assert.InDelta(t, tt.wantP1.Lon, p1.Lon, 1e-6)
+			assert.InDelta(t, tt.wantP2.Lat, p2.Lat, 1e-6)
+			assert.InDelta(t, tt.wantP2.Lon, p2.Lon, 1e-6)
+		})
+	}
+}
+
+func TestLatLon_Gnomonic(t *testing.T) {
+	// Points near the tangent point map close to the origin, at about their true offsets.
+	x, y := cambridge.Gnomonic(cambridge)
+	assert.Equal(t, 0.0, x)
+	assert.Equal(t, 0.0, y)
+	x, y = cambridge.Gnomonic(LatLon{Lat: cambridge.Lat + 1e-4, Lon: cambridge.Lon + 1e-4})
+	assert.InDelta(t, 6.81, x, 0.01)
+	assert.InDelta(t, 11.12, y, 0.01)
+
+	x, y = cambridge.Gnomonic(paris)
+	assert.InDelta(t, 163580.3, x, 0.1)
+	assert.InDelta(t, -370301.1, y, 0.1)
+
+	// Round trip.
+	for _, p := range []LatLon{paris, valley, bxl, {Lat: 10, Lon: 40}, {Lat: 89, Lon: -170}} {
+		x, y := cambridge.Gnomonic(p)
+		got := cambridge.GnomonicInverse(x, y)
+		assert.InDelta(t, p.Lat, got.Lat, 1e-9, "%v", p)
+		assert.InDelta(t, p.Lon, got.Lon, 1e-9, "%v", p)
+	}
+
+	// Points along a great circle project to a straight line.
+	x1, y1 := cambridge.Gnomonic(valley)
+	x2, y2 := cambridge.Gnomonic(bxl)
+	for _, f := range []float64{0.25, 0.5, 0.75} {
+		x, y := cambridge.Gnomonic(valley.IntermediatePointTo(bxl, f))
+		cross := (x2-x1)*(y-y1) - (y2-y1)*(x-x1)
+		assert.InDelta(t, 0, cross/math.Hypot(x2-x1, y2-y1), 1e-6, "fraction %v", f)
+	}
+
+	// The far hemisphere is not representable.
+	x, y = cambridge.Gnomonic(LatLon{Lat: -cambridge.Lat, Lon: cambridge.Lon + 180})
+	assert.True(t, math.IsNaN(x) && math.IsNaN(y))
+	x, y = LatLon{Lat: 0, Lon: 0}.Gnomonic(LatLon{Lat: 0, Lon: 90})
+	assert.True(t, math.IsNaN(x) && math.IsNaN(y))
+}
+
+func TestLatLon_CrossTrackDistanceTo(t *testing.T) {
+	pathStart := LatLon{Lat: 53.3206, Lon: -1.7297}
+	pathEnd := LatLon{Lat: 53.1887, Lon: 0.1334}
+	const earthRadiusMiles = 3959.0
+
+	tests := []struct {
+		name     string
+		point    LatLon
+		wantSign float64
+	}{
+		{name: "path start", point: pathStart, wantSign: 0},
+		{name: "left", point: LatLon{Lat: 53.2611, Lon: -0.7972}, wantSign: -1},
+		{name: "right", point: LatLon{Lat: 53.2511, Lon: -0.7972}, wantSign: +1},
+		{name: "far left", point: LatLon{Lat: 54.0, Lon: -0.8}, wantSign: -1},
+		{name: "far right", point: LatLon{Lat: 52.5, Lon: -0.8}, wantSign: +1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.point.CrossTrackDistanceTo(pathStart, pathEnd)
+			switch tt.wantSign {
+			case -1:
+				assert.Less(t, got, 0.0)
+			case +1:
+				assert.Greater(t, got, 0.0)
+			default:
+				assert.Equal(t, 0.0, got)
+			}
+
+			gotMiles := tt.point.CrossTrackDistanceToRadius(pathStart, pathEnd, earthRadiusMiles)
+			assert.InDelta(t, got*earthRadiusMiles/earthRadius, gotMiles, 1e-9)
+		})
+	}
+
+	// documented example
+	pCurrent := LatLon{Lat: 53.2611, Lon: -0.7972}
+	assert.InDelta(t, -307.5, pCurrent.CrossTrackDistanceTo(pathStart, pathEnd), 0.1)
+	assert.InDelta(t, -0.1911, pCurrent.CrossTrackDistanceToRadius(pathStart, pathEnd, earthRadiusMiles), 0.0001)
+}
+
+func TestLatLon_Rhumb(t *testing.T) {
+	dover := LatLon{Lat: 51.127, Lon: 1.338}
+	calais := LatLon{Lat: 50.964, Lon: 1.853}
+
+	assert.InDelta(t, 40308, dover.RhumbDistanceTo(calais), 1)
+	assert.InDelta(t, 116.7, dover.RhumbBearingTo(calais), 0.05)
+	assert.True(t, math.IsNaN(dover.RhumbBearingTo(dover)))
+
+	got := dover.RhumbDestinationPoint(40300, 116.7)
+	assert.InDelta(t, 50.9642, got.Lat, 5e-5)
+	assert.InDelta(t, 1.8530, got.Lon, 5e-5)
+}
+
+func TestLatLon_RhumbDistanceNM(t *testing.T) {
+	dover := LatLon{Lat: 51.127, Lon: 1.338}
+	calais := LatLon{Lat: 50.964, Lon: 1.853}
+
+	assert.InDelta(t, 21.764, dover.RhumbDistanceNM(calais), 1e-3)
+	assert.InDelta(t, dover.RhumbDistanceNM(calais), calais.RhumbDistanceNM(dover), 1e-9)
+	assert.Equal(t, 0.0, dover.RhumbDistanceNM(dover))
+
+	assert.Equal(t, dover.RhumbDistanceTo(calais), dover.RhumbDistanceToRadius(calais, earthRadius))
+	assert.InDelta(t, 25.048, dover.RhumbDistanceToRadius(calais, 3959), 1e-3) // miles
+}
+
+func TestRhumbBearingMatrix(t *testing.T) {
+	points := []LatLon{{Lat: 51.127, Lon: 1.338}, {Lat: 50.964, Lon: 1.853}, {Lat: -33.9, Lon: 151.2}, {Lat: 51.127, Lon: 1.338}, {Lat: 60, Lon: 1.338}, {Lat: 10, Lon: 180}, {Lat: 10, Lon: -180}}
+
+	m := RhumbBearingMatrix(points)
+	require.Len(t, m, len(points))
+	for i, p1 := range points {
+		require.Len(t, m[i], len(points))
+		for j, p2 := range points {
+			want := p1.RhumbBearingTo(p2)
+			if math.IsNaN(want) {
+				assert.True(t, math.IsNaN(m[i][j]), "[%d][%d]: got %v", i, j, m[i][j])
+				continue
+			}
+			assert.InDelta(t, want, m[i][j], 1e-9, "[%d][%d]", i, j)
+		}
+	}
+	assert.InDelta(t, 116.7, m[0][1], 0.05)
+	assert.InDelta(t, 296.7, m[1][0], 0.05)
+	assert.Equal(t, 0.0, m[0][4])
+	assert.Equal(t, 180.0, m[4][3])
+	assert.True(t, math.IsNaN(m[5][6]))
+
+	assert.Empty(t, RhumbBearingMatrix(nil))
+}
+
+func BenchmarkRhumbBearingMatrix_100(b *testing.B) {
+	points := distanceTargets(100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = RhumbBearingMatrix(points)
+	}
+}
+
+func BenchmarkLatLon_RhumbBearingTo_100x100(b *testing.B) {
+	points := distanceTargets(100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, p1 := range points {
+			for _, p2 := range points {
+				_ = p1.RhumbBearingTo(p2)
+			}
+		}
+	}
+}
+
+func TestLatLon_RhumbSameMeridian(t *testing.T) {
+	south := LatLon{Lat: 50, Lon: 1}
+	north := LatLon{Lat: 52, Lon: 1}
+	arc := 2 * toRadians * earthRadius
+
+	assert.Equal(t, 0.0, south.RhumbBearingTo(north))
+	assert.Equal(t, 180.0, north.RhumbBearingTo(south))
+	assert.InDelta(t, arc, south.RhumbDistanceTo(north), 1e-6)
+	assert.InDelta(t, arc, north.RhumbDistanceTo(south), 1e-6)
+
+	// same meridian either side of the anti-meridian representation
+	assert.Equal(t, 0.0, LatLon{Lat: 10, Lon: 180}.RhumbBearingTo(LatLon{Lat: 20, Lon: -180}))
+	assert.InDelta(t, 5*arc, LatLon{Lat: 10, Lon: 180}.RhumbDistanceTo(LatLon{Lat: 20, Lon: -180}), 1e-6)
+
+	// to the pole
+	assert.Equal(t, 0.0, LatLon{Lat: 89, Lon: 0}.RhumbBearingTo(LatLon{Lat: 90, Lon: 0}))
+	assert.InDelta(t, arc/2, LatLon{Lat: 89, Lon: 0}.RhumbDistanceTo(LatLon{Lat: 90, Lon: 0}), 1e-6)
+
+	// coincident points
+	assert.True(t, math.IsNaN(north.RhumbBearingTo(north)))
+	assert.True(t, math.IsNaN(LatLon{Lat: 10, Lon: 180}.RhumbBearingTo(LatLon{Lat: 10, Lon: -180})))
+	assert.True(t, math.IsNaN(LatLon{Lat: 90, Lon: 0}.RhumbBearingTo(LatLon{Lat: 90, Lon: 45})))
+	assert.Equal(t, 0.0, north.RhumbDistanceTo(north))
+}
+
+func TestLatLon_RhumbLineTo(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   LatLon
+		maxSegment float64
+		wantPoints int
+	}{
+		{name: "self", from: cambridge, to: cambridge, maxSegment: 1000, wantPoints: 2},
+		{name: "dover-calais", from: LatLon{Lat: 51.127, Lon: 1.338}, to: LatLon{Lat: 50.964, Lon: 1.853}, maxSegment: 10e3, wantPoints: 6},
+		{name: "east-west", from: LatLon{Lat: 60, Lon: -10}, to: LatLon{Lat: 60, Lon: 10}, maxSegment: 100e3, wantPoints: 13},
+		{name: "anti-meridian", from: LatLon{Lat: 20, Lon: 170}, to: LatLon{Lat: 30, Lon: -170}, maxSegment: 200e3, wantPoints: 13},
+		{name: "long-haul", from: greenwich, to: LatLon{Lat: 40.6413, Lon: -73.7781}, maxSegment: 100e3, wantPoints: 59},
+		{name: "NaN segment", from: cambridge, to: paris, maxSegment: math.NaN(), wantPoints: 2},
+		{name: "infinite segment", from: cambridge, to: paris, maxSegment: math.Inf(1), wantPoints: 2},
+		{name: "zero segment", from: cambridge, to: paris, maxSegment: 0, wantPoints: 2},
+		{name: "tiny segment", from: LatLon{Lat: 0, Lon: 0}, to: LatLon{Lat: 1, Lon: 1}, maxSegment: 1e-300, wantPoints: maxPathSegments + 1},
+		{name: "subnormal segment", from: cambridge, to: paris, maxSegment: math.SmallestNonzeroFloat64, wantPoints: maxPathSegments + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.RhumbLineTo(tt.to, tt.maxSegment)
+			require.Len(t, got, tt.wantPoints)
+			assert.Equal(t, tt.from, got[0])
+			assert.Equal(t, tt.to, got[len(got)-1])
+			if tt.from == tt.to || !(tt.maxSegment > 0) || len(got) > maxPathSegments {
+				return
+			}
+
+			bearing := tt.from.RhumbBearingTo(tt.to)
+			for i := 1; i < len(got); i++ {
+				assert.LessOrEqual(t, got[i-1].RhumbDistanceTo(got[i]), tt.maxSegment+1e-6)
+				assert.InDelta(t, 0, Wrap180(got[i-1].RhumbBearingTo(got[i])-bearing), 1e-6)
+			}
+		})
+	}
+}
+
+func TestLatLon_RhumbCrossTrackDistanceTo(t *testing.T) {
+	// Sign convention as for CrossTrackDistanceTo: negative to the left of the path, positive to the right.
+	start := LatLon{Lat: 52, Lon: -1}
+	end := start.RhumbDestinationPoint(100e3, 45)
+	onTrack := start.RhumbDestinationPoint(50e3, 45)
+
+	tests := []struct {
+		name       string
+		point      LatLon
+		start, end LatLon
+		want       float64
+	}{
+		{name: "north of eastward track", point: LatLon{Lat: 50.1, Lon: 5}, start: LatLon{Lat: 50, Lon: 0}, end: LatLon{Lat: 50, Lon: 10}, want: -11119.49},
+		{name: "south of eastward track", point: LatLon{Lat: 49.9, Lon: 5}, start: LatLon{Lat: 50, Lon: 0}, end: LatLon{Lat: 50, Lon: 10}, want: 11119.49},
+		{name: "right of north-east track", point: onTrack.RhumbDestinationPoint(1000, 135), start: start, end: end, want: 1000},
+		{name: "left of north-east track", point: onTrack.RhumbDestinationPoint(1000, 315), start: start, end: end, want: -1000},
+		{name: "on track", point: onTrack, start: start, end: end, want: 0},
+		{name: "across anti-meridian", point: LatLon{Lat: 0, Lon: -179.5}, start: LatLon{Lat: 1, Lon: 179}, end: LatLon{Lat: 1, Lon: -179}, want: 111194.93},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.point.RhumbCrossTrackDistanceTo(tt.start, tt.end)
+			assert.InDelta(t, tt.want, got, 0.01)
+		})
+	}
+
+	assert.True(t, math.IsNaN(cambridge.RhumbCrossTrackDistanceTo(paris, paris)))
+}
+
+func TestLatLon_RouteDivergence(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     LatLon
+		distance float64
+		bearing  float64
+		want     float64
+		delta    float64
+	}{
+		{name: "no-op", from: cambridge, distance: 0, bearing: 90, want: 0, delta: 1e-6},
+		{name: "north", from: cambridge, distance: 1000e3, bearing: 0, want: 0, delta: 1e-3},
+		{name: "south", from: cambridge, distance: 1000e3, bearing: 180, want: 0, delta: 1e-3},
+		{name: "equator east", from: LatLon{Lat: 0, Lon: 0}, distance: 1000e3, bearing: 90, want: 0, delta: 1e-3},
+		{name: "east", from: LatLon{Lat: 60, Lon: 0}, distance: 1000e3, bearing: 90, want: 135.4e3, delta: 0.1e3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.from.RouteDivergence(tt.distance, tt.bearing)
+			assert.InDelta(t, tt.want, got, tt.delta)
+		})
+	}
+}
+
 func poly(t *testing.T, name string, s string) []LatLon {
 	points := strings.Split(s, " ")
 	poly := make([]LatLon, len(points))
@@ -126,6 +1049,219 @@ func poly(t *testing.T, name string, s string) []LatLon {
 	return poly
 }
 
+func TestOrientation(t *testing.T) {
+	tests := []struct {
+		name    string
+		polygon string
+		want    int
+	}{
+		{name: "square cw", polygon: "1,1 2,1 2,2 1,2", want: -1},
+		{name: "square ccw", polygon: "1,1 1,2 2,2 2,1", want: +1},
+		{name: "triangle", polygon: "1,1 2,1 1,2", want: -1},
+		{name: "southern hemisphere ccw", polygon: "-2,1 -2,2 -1,2 -1,1", want: +1},
+		{name: "pole eastwards", polygon: "89,0 89,120 89,-120", want: +1},
+		{name: "pole westwards", polygon: "89,0 89,-120 89,120", want: -1},
+		{name: "line", polygon: "45,45 50,50", want: 0},
+		{name: "point", polygon: "1,1", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := poly(t, tt.name, tt.polygon)
+			assert.Equal(t, tt.want, Orientation(p))
+			assert.InDelta(t, float64(tt.want)*AreaOf(p), SignedAreaOf(p), 1.0)
+		})
+	}
+
+	cw, ccw := poly(t, "cw", "1,1 2,1 2,2 1,2"), poly(t, "ccw", "1,1 1,2 2,2 2,1")
+	assert.InDelta(t, -SignedAreaOf(cw), SignedAreaOf(ccw), 1.0)
+}
+
+func TestNormalizePolygon(t *testing.T) {
+	cw := poly(t, "cw", "1,1 2,1 2,2 1,2")
+	ccw := poly(t, "ccw", "1,1 1,2 2,2 2,1")
+
+	assert.Equal(t, poly(t, "want", "1,1 1,2 2,2 2,1 1,1"), NormalizePolygon(cw), "clockwise is reversed")
+	assert.Equal(t, poly(t, "cw", "1,1 2,1 2,2 1,2"), cw, "polygon should not be modified")
+	assert.Equal(t, poly(t, "want", "1,1 1,2 2,2 2,1 1,1"), NormalizePolygon(ccw), "counter-clockwise is only closed")
+	assert.Equal(t, poly(t, "want", "1,1 1,2 2,2 2,1 1,1"), NormalizePolygon(poly(t, "closed", "1,1 1,2 2,2 2,1 1,1")))
+
+	for _, p := range [][]LatLon{cw, ccw, poly(t, "pole", "89,0 89,-120 89,120")} {
+		n := NormalizePolygon(p)
+		assert.Equal(t, 1, Orientation(n))
+		assert.InDelta(t, AreaOf(p), SignedAreaOf(n), 1.0)
+	}
+}
+
+func TestDensify(t *testing.T) {
+	// an octant-sized triangle, with edges of up to 10,000km
+	triangle := []LatLon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 90}, {Lat: 60, Lon: 45}}
+	area := AreaOf(triangle)
+
+	prev := area
+	for _, maxEdge := range []float64{1000e3, 100e3, 10e3} {
+		dense := Densify(triangle, maxEdge)
+		assert.Equal(t, triangle[0], dense[0])
+		assert.Equal(t, dense[0], dense[len(dense)-1], "closed")
+		for i := 0; i < len(dense)-1; i++ {
+			assert.LessOrEqual(t, dense[i].DistanceTo(dense[i+1]), maxEdge*(1+1e-9))
+		}
+
+		// the new vertices lie on the great circle edges, so the area converges (immediately) on
+		// that of the original triangle
+		got := AreaOf(dense)
+		assert.InEpsilon(t, area, got, 1e-9, "maxEdge %v", maxEdge)
+		assert.InEpsilon(t, prev, got, 1e-9, "maxEdge %v", maxEdge)
+		prev = got
+	}
+
+	assert.Len(t, Densify([]LatLon{{Lat: 0, Lon: 0}, {Lat: 0, Lon: 1}, {Lat: 1, Lon: 0}}, 50e3), 11)
+	assert.Equal(t, closedRing(triangle), Densify(triangle, 0))
+	assert.Empty(t, Densify(nil, 1000))
+}
+
+func TestAreaOfRings(t *testing.T) {
+	outer := poly(t, "outer", "0,0 0,3 3,3 3,0")
+	hole := poly(t, "hole", "1,1 1,2 2,2 2,1")
+	hole2 := poly(t, "hole2", "0.25,0.25 0.25,0.75 0.75,0.75 0.75,0.25")
+	outside := poly(t, "outside", "5,5 5,6 6,6 6,5")
+	straddling := poly(t, "straddling", "2,2 2,4 4,4 4,2")
+
+	tests := []struct {
+		name  string
+		holes [][]LatLon
+		want  float64
+	}{
+		{name: "no holes", holes: nil, want: AreaOf(outer)},
+		{name: "one hole", holes: [][]LatLon{hole}, want: AreaOf(outer) - AreaOf(hole)},
+		{name: "two holes", holes: [][]LatLon{hole, hole2}, want: AreaOf(outer) - AreaOf(hole) - AreaOf(hole2)},
+		{name: "hole outside", holes: [][]LatLon{outside}, want: AreaOf(outer)},
+		{name: "hole straddling", holes: [][]LatLon{hole, straddling}, want: AreaOf(outer) - AreaOf(hole)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AreaOfRings(outer, tt.holes)
+			assert.InDelta(t, tt.want, got, 1.0)
+		})
+	}
+
+	assert.InDelta(t, 9.89e10, AreaOfRings(outer, [][]LatLon{hole}), 0.01e10)
+}
+
+func TestLatLon_Equals(t *testing.T) {
+	assert.True(t, cambridge.Equals(cambridge))
+	assert.True(t, cambridge.Equals(LatLon{Lat: cambridge.Lat + 1e-13, Lon: cambridge.Lon - 1e-13}))
+	assert.False(t, cambridge.Equals(LatLon{Lat: cambridge.Lat + 1e-9, Lon: cambridge.Lon}))
+	assert.False(t, cambridge.Equals(LatLon{Lat: cambridge.Lat, Lon: cambridge.Lon + 1e-9}))
+	assert.False(t, cambridge.Equals(paris))
+}
+
+func TestLatLon_DMSParts(t *testing.T) {
+	type parts struct {
+		latD, latM int
+		latS       float64
+		latHem     byte
+		lonD, lonM int
+		lonS       float64
+		lonHem     byte
+	}
+	tests := []struct {
+		name  string
+		point LatLon
+		want  parts
+	}{
+		{name: "greenwich", point: greenwich, want: parts{51, 28, 40.368, 'N', 0, 0, 5.292, 'W'}},
+		{name: "sydney", point: LatLon{Lat: -33.8568, Lon: 151.2153}, want: parts{33, 51, 24.48, 'S', 151, 12, 55.08, 'E'}},
+		{name: "seconds carry", point: LatLon{Lat: 51.99999999999, Lon: -0.99999999999}, want: parts{52, 0, 0, 'N', 1, 0, 0, 'W'}},
+		{name: "origin", point: LatLon{}, want: parts{0, 0, 0, 'N', 0, 0, 0, 'E'}},
+		{name: "wrapped", point: LatLon{Lat: 0, Lon: 190}, want: parts{0, 0, 0, 'N', 170, 0, 0, 'W'}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got parts
+			got.latD, got.latM, got.latS, got.latHem, got.lonD, got.lonM, got.lonS, got.lonHem = tt.point.DMSParts()
+			assert.Equal(t, tt.want.latD, got.latD)
+			assert.Equal(t, tt.want.latM, got.latM)
+			assert.InDelta(t, tt.want.latS, got.latS, 1e-6)
+			assert.Equal(t, string(tt.want.latHem), string(got.latHem))
+			assert.Equal(t, tt.want.lonD, got.lonD)
+			assert.Equal(t, tt.want.lonM, got.lonM)
+			assert.InDelta(t, tt.want.lonS, got.lonS, 1e-6)
+			assert.Equal(t, string(tt.want.lonHem), string(got.lonHem))
+		})
+	}
+}
+
+func TestLatLon_FormatWith(t *testing.T) {
+	sydney := LatLon{Lat: -33.8568, Lon: 151.2153}
+
+	tests := []struct {
+		name  string
+		point LatLon
+		opts  FormatOptions
+		want  string
+	}{
+		{name: "tab-separated numeric", point: greenwich, opts: FormatOptions{Mode: "n", DP: 4, Separator: "\t"}, want: "51.4779\t-0.0015"},
+		{name: "numeric default dp", point: sydney, opts: FormatOptions{Mode: "n", DP: -1}, want: "-33.8568, 151.2153"},
+		{name: "dms with symbols", point: greenwich, opts: FormatOptions{Mode: "dms", DP: 2, UseSymbols: true}, want: "51°28′40.37″N, 000°00′05.29″W"},
+		{name: "dms southern/eastern", point: sydney, opts: FormatOptions{Mode: "dms", DP: -1, UseSymbols: true}, want: "33°51′24″S, 151°12′55″E"},
+		{name: "dms without symbols", point: greenwich, opts: FormatOptions{Mode: "dms", DP: 0, Separator: " "}, want: "51 28 40 N 000 00 05 W"},
+		{name: "dm", point: greenwich, opts: FormatOptions{Mode: "dm", DP: -1, UseSymbols: true}, want: "51°28.67′N, 000°00.09′W"},
+		{name: "default mode", point: greenwich, opts: FormatOptions{DP: -1, UseSymbols: true}, want: "51.4779°N, 000.0015°W"},
+		{name: "rounds up to whole degrees", point: LatLon{Lat: 5.99999999, Lon: -179.9999999}, opts: FormatOptions{Mode: "dms", DP: 2, UseSymbols: true}, want: "06°00′00.00″N, 180°00′00.00″W"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.point.FormatWith(tt.opts))
+		})
+	}
+
+	// formatted DMS can be parsed back
+	got, err := ParseLatLon(greenwich.FormatWith(FormatOptions{Mode: "dms", DP: 4, UseSymbols: true}), 0, WGS84)
+	require.NoError(t, err)
+	assert.InDelta(t, greenwich.Lat, got.Lat, 1e-7)
+	assert.InDelta(t, greenwich.Lon, got.Lon, 1e-7)
+}
+
+func TestLatLon_Less(t *testing.T) {
+	assert.True(t, paris.Less(cambridge))
+	assert.False(t, cambridge.Less(paris))
+	assert.True(t, LatLon{Lat: 52, Lon: 0.1}.Less(LatLon{Lat: 52, Lon: 0.2}))
+	assert.False(t, LatLon{Lat: 52, Lon: 0.2}.Less(LatLon{Lat: 52, Lon: 0.1}))
+	assert.False(t, cambridge.Less(cambridge))
+}
+
+func TestSortPoints(t *testing.T) {
+	want := []LatLon{
+		{Lat: -33.9, Lon: 151.2},
+		{Lat: 0, Lon: -10},
+		{Lat: 0, Lon: 0},
+		{Lat: 0, Lon: 10},
+		paris,
+		greenwich,
+		stansted,
+		cambridge,
+		{Lat: 90, Lon: 0},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		points := append([]LatLon(nil), want...)
+		r.Shuffle(len(points), func(i, j int) { points[i], points[j] = points[j], points[i] })
+		SortPoints(points)
+		assert.Equal(t, want, points)
+	}
+}
+
+func TestLatLon_AreaOf_RoundedClosure(t *testing.T) {
+	square := []LatLon{{Lat: 1, Lon: 1}, {Lat: 2, Lon: 1}, {Lat: 2, Lon: 2}, {Lat: 1, Lon: 2}}
+	nearlyClosed := append(append([]LatLon{}, square...), LatLon{Lat: 1 + 1e-13, Lon: 1 - 1e-13})
+
+	assert.InDelta(t, 12360230987, AreaOf(nearlyClosed), 1.0)
+	assert.Equal(t, AreaOf(square), AreaOf(nearlyClosed))
+	assert.Len(t, nearlyClosed, 5, "polygon should not be modified")
+	assert.Equal(t, LatLon{Lat: 1 + 1e-13, Lon: 1 - 1e-13}, nearlyClosed[4], "polygon should not be modified")
+}
+
 func TestLatLon_AreaOf(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -139,6 +1275,9 @@ func TestLatLon_AreaOf(t *testing.T) {
 		{name: "square ccw", polygon: "1,1 1,2 2,2 2,1", want: 12360230987},
 		{name: "pole", polygon: "89,0 89,120 89,-120", want: 16063139192},
 		{name: "concave", polygon: "1,1 5,1 5,3 1,3 3,2", want: 74042699236},
+		{name: "duplicate vertices", polygon: "1,1 2,1 2,1 2,2 1,2 1,2", want: 12360230987},
+		{name: "point", polygon: "1,1", want: 0},
+		{name: "point repeated", polygon: "1,1 1,1 1,1", want: 0},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,76 @@
+package osgridref
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// csvHeader is the header row written by WriteCSV and expected by ReadCSV.
+var csvHeader = []string{"gridref", "easting", "northing", "lat", "lon"}
+
+// WriteCSV writes the grid references as CSV, with a header row followed by one row per reference
+// with the columns "gridref,easting,northing,lat,lon". The grid reference is written as a compact
+// 10-digit reference, and the lat/lon (WGS84) to 6 decimal places.
+func WriteCSV(w io.Writer, refs []OsGridRef) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, o := range refs {
+		lat, lon := o.ToLatLon()
+		record := []string{
+			o.StringNCompact(10),
+			strconv.Itoa(o.Easting),
+			strconv.Itoa(o.Northing),
+			strconv.FormatFloat(lat, 'f', 6, 64),
+			strconv.FormatFloat(lon, 'f', 6, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadCSV reads grid references from CSV in the format written by WriteCSV. The easting and
+// northing columns are used to construct each reference; the other columns are ignored.
+func ReadCSV(r io.Reader) ([]OsGridRef, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(csvHeader)
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("missing CSV header")
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range csvHeader {
+		if header[i] != csvHeader[i] {
+			return nil, fmt.Errorf("invalid CSV header: expected column %d to be %q, got %q", i+1, csvHeader[i], header[i])
+		}
+	}
+
+	var refs []OsGridRef
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return refs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		e, err1 := strconv.Atoi(record[1])
+		n, err2 := strconv.Atoi(record[2])
+		if err1 != nil || err2 != nil {
+			return nil, fmt.Errorf("invalid easting/northing in row %d: %q, %q", row, record[1], record[2])
+		}
+		refs = append(refs, OsGridRef{Easting: e, Northing: n})
+	}
+}
@@ -0,0 +1,65 @@
+package osgridref
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	refs := []OsGridRef{
+		{Easting: 146760, Northing: 28548},
+		{Easting: 544982, Northing: 257869},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, refs))
+	assert.Equal(t, "gridref,easting,northing,lat,lon\n"+
+		"SW4676028548,146760,28548,50.102909,-5.542765\n"+
+		"TL4498257869,544982,257869,52.199992,0.119990\n", buf.String())
+}
+
+func TestCSV_RoundTrip(t *testing.T) {
+	var refs []OsGridRef
+	for _, s := range []string{"SW 46760 28548", "TL4498257869", "ST1784076329", "NJ9439206608", "SJ 92395 52997", "SV 0 0"} {
+		o, err := ParseOsGridRef(s)
+		require.NoError(t, err)
+		refs = append(refs, o)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, refs))
+	got, err := ReadCSV(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, refs, got)
+}
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		want    []OsGridRef
+		wantErr bool
+	}{
+		{name: "header only", csv: "gridref,easting,northing,lat,lon\n", want: nil},
+		{name: "quoted", csv: "gridref,easting,northing,lat,lon\n\"SW 46760 28548\",\"146760\",28548,,\n", want: []OsGridRef{{Easting: 146760, Northing: 28548}}},
+		{name: "empty", csv: "", wantErr: true},
+		{name: "bad header", csv: "gridref,northing,easting,lat,lon\n", wantErr: true},
+		{name: "missing column", csv: "gridref,easting,northing,lat,lon\nSW4676028548,146760,28548,50.102904\n", wantErr: true},
+		{name: "bad easting", csv: "gridref,easting,northing,lat,lon\nSW4676028548,14676O,28548,50.102904,-5.542765\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadCSV(strings.NewReader(tt.csv))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
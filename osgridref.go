@@ -1,9 +1,12 @@
 package osgridref
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"math/rand"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -46,7 +49,7 @@ const (
 	toRadians = math.Pi / 180.0
 	toDegrees = 180.0 / math.Pi
 
-	// Airy 1830 major & minor semi-axes
+	// Airy 1830 major & minor semi-axes (see also nationalGrid, which projects using these)
 	a = 6377563.396
 	b = 6356256.909
 
@@ -64,10 +67,20 @@ const (
 	// eccentricity squared
 	e2 = 1.0 - (b*b)/(a*a)
 
-	// n, n², n³
-	n  = (a - b) / (a + b)
-	n2 = n * n
-	n3 = n * n * n
+	// default tolerance for ToLatLon's iterative calculation of latitude: 0.01mm
+	defaultTolerance = 0.00001
+
+	// maximum iterations for the calculation of latitude, in case of non-convergence
+	maxIterations = 20
+
+	// interval between vertices of the lines generated by GridLines, metres
+	gridLineSample = 10_000
+
+	// smallest spacing accepted by GridLines, metres
+	minGridLineSpacing = 1000
+
+	// nominal accuracy of the Helmert transformation from OSGB36 to WGS84, metres
+	helmertAccuracy = 5
 )
 
 // OsGridRef represents an Ordnance Survey grid reference.
@@ -75,15 +88,64 @@ type OsGridRef struct {
 	Easting, Northing int
 }
 
+// NewOsGridRef returns the OsGridRef with the given easting and northing (in metres from the false
+// origin of the grid), or an error if they are outside the grid. Constructing an OsGridRef directly
+// avoids the check.
+func NewOsGridRef(easting, northing int) (OsGridRef, error) {
+	o := OsGridRef{Easting: easting, Northing: northing}
+	if !o.Valid() {
+		return OsGridRef{}, fmt.Errorf("%w: easting %d, northing %d outside OS grid", ErrOutOfRange, easting, northing)
+	}
+	return o, nil
+}
+
+// RandomOsGridRef returns a grid reference chosen uniformly from the whole 700km × 1300km extent of
+// the grid, to the metre, using r as the source of randomness. It is intended for testing.
+func RandomOsGridRef(r *rand.Rand) OsGridRef {
+	return OsGridRef{Easting: r.Intn(700_000), Northing: r.Intn(1_300_000)}
+}
+
+// RandomLatLonInGB returns the WGS84 lat/lon of a grid reference from RandomOsGridRef. Note that
+// this samples the extent of the grid, much of which is sea, rather than the land of Great Britain.
+func RandomLatLonInGB(r *rand.Rand) LatLon {
+	return RandomOsGridRef(r).ToLatLonStruct()
+}
+
 var (
-	commaSeparatedFormat = regexp.MustCompile(`^(\d+),\s*(\d+)$`)
+	commaSeparatedFormat = regexp.MustCompile(`^(\d+)(?:M|METRES)?,\s*(\d+)(?:M|METRES)?$`)
 	gridRefFormat        = regexp.MustCompile(`^[A-Z]{2}[0-9]+$`)
+	tetradFormat         = regexp.MustCompile(`^([A-Z]{2}[0-9]{2})([A-NP-Z])$`)
 )
 
+// tetradLetters are the DINTY letters identifying the 2km squares (tetrads) within a 10km square,
+// in order up each column from the south-west corner, then column by column eastwards:
+//   E J P U Z
+//   D I N T Y
+//   C H M S X
+//   B G L R W
+//   A F K Q V
+const tetradLetters = "ABCDEFGHIJKLMNPQRSTUVWXYZ"
+
+// gridRefSeparators removes the separators, other than spaces, allowed between the parts of a
+// lettered grid reference.
+var gridRefSeparators = strings.NewReplacer("-", "", "/", "")
+
 // ParseOsGridRef parses a string into an OsGridRef.
-// The string may be in comma-separated Easting,Northing format,
-// or with grid letters.
+// The string may be in comma-separated Easting,Northing format (where each value may
+// optionally be suffixed by "m" or "metres"), or with grid letters. With grid letters, at most 10 digits (i.e. 1 metre resolution) may follow,
+// and the letters and digits may be separated by spaces, hyphens or slashes (e.g. "SW-4676-2854").
+// A 10km reference may be followed by a tetrad letter identifying a 2km square within it, as used
+// in biological recording (e.g. "SP98Q"); the result is the south-west corner of the tetrad.
 func ParseOsGridRef(s string) (OsGridRef, error) {
+	o, _, err := ParseOsGridRefWithPrecision(s)
+	return o, err
+}
+
+// ParseOsGridRefWithPrecision is equivalent to ParseOsGridRef, but also returns the resolution of
+// the reference in metres, as implied by the number of digits given: for example 1000 for a
+// 4-figure reference such as "ST 17 76", or 1 for a 10-figure reference. Comma-separated
+// Easting,Northing references always have a resolution of 1 metre.
+func ParseOsGridRefWithPrecision(s string) (OsGridRef, int, error) {
 	s = strings.ReplaceAll(s, " ", "")
 	s = strings.ToUpper(s)
 
@@ -92,17 +154,29 @@ func ParseOsGridRef(s string) (OsGridRef, error) {
 		e, err1 := strconv.ParseFloat(matches[1], 32)
 		n, err2 := strconv.ParseFloat(matches[2], 32)
 		if err1 != nil || err2 != nil {
-			return OsGridRef{}, fmt.Errorf("invalid comma-separated grid ref format: %q", s)
+			return OsGridRef{}, 0, fmt.Errorf("%w: invalid comma-separated format %q", ErrInvalidGridRef, s)
 		}
 		return OsGridRef{
 			Easting:  int(e),
 			Northing: int(n),
-		}, nil
+		}, 1, nil
+	}
+
+	// gazetteers sometimes separate the letters and digits with hyphens or slashes, e.g. "SW-4676-2854"
+	s = gridRefSeparators.Replace(s)
+
+	if tetrad := tetradFormat.FindStringSubmatch(s); len(tetrad) > 0 {
+		o, _, err := ParseOsGridRefWithPrecision(tetrad[1])
+		if err != nil {
+			return OsGridRef{}, 0, err
+		}
+		i := strings.IndexByte(tetradLetters, tetrad[2][0])
+		return OsGridRef{Easting: o.Easting + (i/5)*2000, Northing: o.Northing + (i%5)*2000}, 2000, nil
 	}
 
 	matches = gridRefFormat.FindStringSubmatch(s)
 	if len(matches) == 0 {
-		return OsGridRef{}, fmt.Errorf("invalid grid ref format: %q", s)
+		return OsGridRef{}, 0, fmt.Errorf("%w: invalid format %q", ErrInvalidGridRef, s)
 	}
 
 	// get numeric values of letter references, mapping A->0, B->1, C->2, etc:
@@ -110,7 +184,7 @@ func ParseOsGridRef(s string) (OsGridRef, error) {
 	l2 := int(s[1] - 'A')
 	// shuffle down letters after 'I' since 'I' is not used in grid:
 	if s[0] == 'I' || s[1] == 'I' {
-		return OsGridRef{}, fmt.Errorf("invalid grid ref format: %q", s)
+		return OsGridRef{}, 0, fmt.Errorf("%w: invalid format %q", ErrInvalidGridRef, s)
 	}
 
 	if l1 > 7 {
@@ -120,21 +194,35 @@ func ParseOsGridRef(s string) (OsGridRef, error) {
 		l2--
 	}
 
-	// sanity check
-	if l1 < 8 || l1 > 18 {
-		return OsGridRef{}, fmt.Errorf(`invalid grid reference %q`, s)
-	}
-
 	// convert grid letters into 100km-square indexes from false origin (grid square SV):
 	e100km := ((l1-2)%5)*5 + (l2 % 5)
 	n100km := (19 - (l1/5)*5) - (l2 / 5)
 
+	// the whole square, from its south-west to its north-east corner, must lie within the grid;
+	// the letters alone allow squares such as HA (north of HP) or JZ (far out in the North Sea)
+	sw := OsGridRef{Easting: e100km * 100000, Northing: n100km * 100000}
+	ne := OsGridRef{Easting: sw.Easting + 100000, Northing: sw.Northing + 100000}
+	if !sw.Valid() || !ne.Valid() {
+		return OsGridRef{}, 0, fmt.Errorf("%w: grid square %s of %q lies outside the National Grid", ErrOutOfRange, s[:2], s)
+	}
+
 	// skip grid letters to get numeric (easting/northing) part of ref
 	digits := s[2:]
+	// a reference within a 100km square has at most 5 digits each for easting & northing (i.e.
+	// metres); anything longer is most likely a full-grid easting/northing after the grid letters
+	if len(digits) > 10 {
+		return OsGridRef{}, 0, fmt.Errorf("%w %q: too many digits (at most 10 allowed after grid letters)", ErrInvalidGridRef, s)
+	}
 	// split half way
 	e, n := digits[:len(digits)/2], digits[len(digits)/2:]
 	if len(e) != len(n) {
-		return OsGridRef{}, fmt.Errorf(`invalid grid reference %q`, s)
+		return OsGridRef{}, 0, fmt.Errorf("%w %q", ErrInvalidGridRef, s)
+	}
+
+	// resolution in metres is determined by the number of digits given
+	precision := 1
+	for i := len(e); i < 5; i++ {
+		precision *= 10
 	}
 
 	// standardise to 10-digit refs (metres)
@@ -144,7 +232,124 @@ func ParseOsGridRef(s string) (OsGridRef, error) {
 	easting, _ := strconv.ParseInt(e, 10, 32)
 	northing, _ := strconv.ParseInt(n, 10, 32)
 
-	return OsGridRef{Easting: e100km*100000 + int(easting), Northing: n100km*100000 + int(northing)}, nil
+	return OsGridRef{Easting: e100km*100000 + int(easting), Northing: n100km*100000 + int(northing)}, precision, nil
+}
+
+// ctxCheckInterval is how many items batch functions process between checks of their context.
+const ctxCheckInterval = 1000
+
+// ParseOsGridRefsCtx parses each of refs as ParseOsGridRef does, returning the grid references and
+// errors in slices parallel to refs. The context is checked every 1000 refs; if it is done, parsing
+// stops and each remaining ref is given a zero OsGridRef and the context's error.
+func ParseOsGridRefsCtx(ctx context.Context, refs []string) ([]OsGridRef, []error) {
+	gridRefs := make([]OsGridRef, len(refs))
+	errs := make([]error, len(refs))
+
+	for i, s := range refs {
+		if i%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				for j := i; j < len(refs); j++ {
+					errs[j] = err
+				}
+				break
+			}
+		}
+		gridRefs[i], errs[i] = ParseOsGridRef(s)
+	}
+
+	return gridRefs, errs
+}
+
+// ParseLocation parses a location given either as an OS grid reference or as a lat/lon, returning
+// the WGS84 lat/lon. It is intended for a single search box accepting either form.
+//
+// The string is first tried as a grid reference (see ParseOsGridRef), then as a lat/lon (see
+// ParseLatLon, taken as WGS84). Since a comma-separated pair of whole numbers is valid as either an
+// easting,northing or a lat,lon, such a pair is taken as a lat/lon if it can be one (i.e. the
+// first value is at most 90 and the second at most 180); no land lies within 180m of the grid's
+// false origin, so this does not exclude useful grid references.
+func ParseLocation(s string) (LatLon, error) {
+	if o, err := ParseOsGridRef(s); err == nil && o.Valid() {
+		normalised := strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+		if gridRefFormat.MatchString(normalised) || o.Easting > 90 || o.Northing > 180 {
+			lat, lon := o.ToLatLon()
+			return LatLon{Lat: lat, Lon: lon}, nil
+		}
+	}
+
+	if ll, err := ParseLatLon(s, 0, WGS84); err == nil {
+		return LatLon{Lat: ll.Lat, Lon: ll.Lon}, nil
+	}
+
+	return LatLon{}, fmt.Errorf("invalid location: %q", s)
+}
+
+// EqualsWithin reports whether the other grid reference is within the given number of metres of
+// this one (measured as a straight line on the grid). This is useful when comparing references
+// for the same location that have been derived by slightly different processing (for example
+// one recorded in OSGB36 and one derived from a WGS84 position). References given to different
+// precisions should first be brought to a common resolution using Truncate.
+func (o OsGridRef) EqualsWithin(other OsGridRef, metres int) bool {
+	dE := float64(o.Easting - other.Easting)
+	dN := float64(o.Northing - other.Northing)
+	return math.Sqrt(dE*dE+dN*dN) <= float64(metres)
+}
+
+// Truncate returns the grid reference reduced to the given resolution in metres (for example 1000
+// for a 4-figure reference), i.e. the south-west corner of the grid square of that size containing
+// this reference. This matches how a shorter grid reference is read.
+func (o OsGridRef) Truncate(resolution int) OsGridRef {
+	if resolution <= 1 {
+		return o
+	}
+	return OsGridRef{
+		Easting:  o.Easting - o.Easting%resolution,
+		Northing: o.Northing - o.Northing%resolution,
+	}
+}
+
+// CellCorners returns the south-west, south-east, north-east and north-west corners, in that order,
+// of the square cell of the given size in metres (for example 1000 for a 4-figure reference)
+// containing this reference. The south-west corner is Truncate(resolutionMetres). A resolution
+// less than 1 metre is taken to be 1 metre.
+func (o OsGridRef) CellCorners(resolutionMetres int) [4]OsGridRef {
+	if resolutionMetres < 1 {
+		resolutionMetres = 1
+	}
+	sw := o.Truncate(resolutionMetres)
+	return [4]OsGridRef{
+		sw,
+		{Easting: sw.Easting + resolutionMetres, Northing: sw.Northing},
+		{Easting: sw.Easting + resolutionMetres, Northing: sw.Northing + resolutionMetres},
+		{Easting: sw.Easting, Northing: sw.Northing + resolutionMetres},
+	}
+}
+
+// Compare returns -1, 0 or +1 according to whether this grid reference sorts before, the same as,
+// or after the other: references are ordered by northing, then by easting, i.e. in rows from south
+// to north, each row from west to east (the order of CellsInBox).
+func (o OsGridRef) Compare(other OsGridRef) int {
+	switch {
+	case o.Northing != other.Northing:
+		if o.Northing < other.Northing {
+			return -1
+		}
+		return +1
+	case o.Easting < other.Easting:
+		return -1
+	case o.Easting > other.Easting:
+		return +1
+	default:
+		return 0
+	}
+}
+
+// SortOsGridRefs sorts grid references in place into the order defined by Compare, for example so
+// that they can be searched with sort.Search.
+func SortOsGridRefs(refs []OsGridRef) {
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Compare(refs[j]) < 0
+	})
 }
 
 func (o OsGridRef) Valid() bool {
@@ -159,63 +364,103 @@ func (o OsGridRef) assertValid() {
 // ToLatLon converts the OS grid reference to a lat/lon based on the WGS84 datum (i.e. the one normally used
 // in GPS services, or global mapping systems).
 func (o OsGridRef) ToLatLon() (float64, float64) {
-	E := float64(o.Easting)
-	N := float64(o.Northing)
+	return o.ToLatLonTol(defaultTolerance)
+}
 
-	φ := φ0
-	M := float64(0)
+// ToLatLonStruct is equivalent to ToLatLon, but returns the result as a LatLon, so that it can be
+// used directly with the spherical methods, for example o.ToLatLonStruct().DistanceTo(other).
+func (o OsGridRef) ToLatLonStruct() LatLon {
+	lat, lon := o.ToLatLon()
+	return LatLon{Lat: lat, Lon: lon}
+}
 
-	for {
-		φ = (N-N0-M)/(a*F0) + φ
+// ToLatLonTol is equivalent to ToLatLon, but iterates the meridional arc calculation only until it
+// is within tolMetres, allowing callers to trade accuracy for speed. ToLatLon uses a tolerance of
+// 0.01mm. In any case, at most 20 iterations are performed.
+func (o OsGridRef) ToLatLonTol(tolMetres float64) (float64, float64) {
+	lat, lon, _ := o.toLatLon(tolMetres, WGS84)
+	return lat, lon
+}
 
-		Ma := (1 + n + (5/4)*n2 + (5/4)*n3) * (φ - φ0)
-		Mb := (3*n + 3*n*n + (21/8)*n3) * math.Sin(φ-φ0) * math.Cos(φ+φ0)
-		Mc := ((15/8)*n2 + (15/8)*n3) * math.Sin(2*(φ-φ0)) * math.Cos(2*(φ+φ0))
-		Md := (35 / 24) * n3 * math.Sin(3*(φ-φ0)) * math.Cos(3*(φ+φ0))
-		M = b * F0 * (Ma - Mb + Mc - Md) // meridional arc
+// ToLatLonETRS89 converts the OS grid reference to a lat/lon based on the ETRS89 datum, as used by
+// modern OS data (such as OS Open Data). At the 1-metre level, ETRS89 is coincident with WGS84,
+// so this will agree with ToLatLon to within about a metre.
+func (o OsGridRef) ToLatLonETRS89() (float64, float64) {
+	lat, lon, _ := o.toLatLon(defaultTolerance, Datums["ETRS89"])
+	return lat, lon
+}
 
-		// until < 0.01mm
-		if math.Abs(N-N0-M) < 0.00001 {
-			break
-		}
+// toLatLon performs the conversion for ToLatLonTol into the given datum, additionally returning
+// the number of iterations taken.
+func (o OsGridRef) toLatLon(tolMetres float64, datum Datum) (float64, float64, int) {
+	lat, lon, iterations := nationalGrid.inverse(float64(o.Easting), float64(o.Northing), tolMetres)
+
+	// That has calculated the lat/lon in OSGB36; convert to the requested datum
+	lat, lon = osgb36To(lat, lon, datum)
+
+	return lat, lon, iterations
+}
+
+// ToLatLonRounded is equivalent to ToLatLon, but with both latitude and longitude rounded to the
+// requested number of decimal places. Halfway values are rounded to even.
+func (o OsGridRef) ToLatLonRounded(decimalPlaces int) (float64, float64) {
+	lat, lon := o.ToLatLon()
+	scale := math.Pow10(decimalPlaces)
+	return math.RoundToEven(lat*scale) / scale, math.RoundToEven(lon*scale) / scale
+}
+
+// ToLatLonWithAccuracy is equivalent to ToLatLon, but also returns an estimate of the accuracy of
+// the result in metres: the larger of the nominal accuracy of the Helmert transformation used (5
+// metres) and the size of the grid square the reference denotes.
+//
+// An OsGridRef does not record its precision, so the square size is implied by the easting and
+// northing: it is the largest power of ten, up to 100km, dividing both. A reference parsed from
+// "TL 44 57" is therefore taken to be accurate to 1km, but so is a 1-metre reference which happens
+// to fall on a kilometre line; use ParseOsGridRefWithPrecision where the true precision matters.
+func (o OsGridRef) ToLatLonWithAccuracy() (lat, lon, accuracyMetres float64) {
+	lat, lon = o.ToLatLon()
+	return lat, lon, math.Max(helmertAccuracy, float64(o.impliedResolution()))
+}
+
+// impliedResolution returns the largest power of ten, from 1 to 100000 metres, dividing both the
+// easting and northing.
+func (o OsGridRef) impliedResolution() int {
+	resolution := 1
+	for resolution < 100000 && o.Easting%(resolution*10) == 0 && o.Northing%(resolution*10) == 0 {
+		resolution *= 10
 	}
+	return resolution
+}
+
+// GridConvergence returns the grid convergence at this grid reference, in degrees: the angle
+// between true north and grid north. It is positive east of the central meridian (2°W), where
+// grid north lies clockwise of true north; a true bearing is the grid bearing plus the convergence.
+//
+// q.v. Ordnance Survey ‘A guide to coordinate systems in Great Britain’, Annex C.
+func (o OsGridRef) GridConvergence() float64 {
+	return nationalGrid.convergence(float64(o.Easting), float64(o.Northing))
+}
+
+// ScaleFactor returns the point scale factor of the projection at this grid reference: the ratio
+// of a (short) distance measured on the grid to the true distance on the ellipsoid. It is F0
+// (0.9996012717) on the central meridian (2°W), increasing towards the east and west edges of
+// the grid.
+//
+// q.v. Ordnance Survey ‘A guide to coordinate systems in Great Britain’, Annex C.
+func (o OsGridRef) ScaleFactor() float64 {
+	return nationalGrid.scaleFactor(float64(o.Easting), float64(o.Northing))
+}
 
-	cosφ := math.Cos(φ)
-	sinφ := math.Sin(φ)
-	ν := a * F0 / math.Sqrt(1-e2*sinφ*sinφ)                   // nu = transverse radius of curvature
-	ρ := a * F0 * (1 - e2) / math.Pow(1-e2*sinφ*sinφ, 1.5) // rho = meridional radius of curvature
-	η2 := ν/ρ - 1                                                // eta = ?
-
-	tanφ := math.Tan(φ)
-	tan2φ := tanφ * tanφ
-	tan4φ := tan2φ * tan2φ
-	tan6φ := tan4φ * tan2φ
-	secφ := 1 / cosφ
-	ν3 := ν * ν * ν
-	ν5 := ν3 * ν * ν
-	ν7 := ν5 * ν * ν
-	VII := tanφ / (2 * ρ * ν)
-	VIII := tanφ / (24 * ρ * ν3) * (5 + 3*tan2φ + η2 - 9*tan2φ*η2)
-	IX := tanφ / (720 * ρ * ν5) * (61 + 90*tan2φ + 45*tan4φ)
-	X := secφ / ν
-	XI := secφ / (6 * ν3) * (ν/ρ + 2*tan2φ)
-	XII := secφ / (120 * ν5) * (5 + 28*tan2φ + 24*tan4φ)
-	XIIA := secφ / (5040 * ν7) * (61 + 662*tan2φ + 1320*tan4φ + 720*tan6φ)
-
-	dE := E - E0
-	dE2 := dE * dE
-	dE3 := dE2 * dE
-	dE4 := dE2 * dE2
-	dE5 := dE3 * dE2
-	dE6 := dE4 * dE2
-	dE7 := dE5 * dE2
-	φ = φ - VII*dE2 + VIII*dE4 - IX*dE6
-	λ := λ0 + X*dE - XI*dE3 + XII*dE5 - XIIA*dE7
-
-	// That has calculated the lat/lon in OSGB36; we want WGS84
-	φ, λ = osgb36ToWGS84(φ*toDegrees, λ*toDegrees)
-
-	return φ, λ
+// RouteStats converts this and the other grid reference to (WGS84) lat/lon and returns the
+// great-circle and rhumb line distances between them, in metres, together with the initial
+// great-circle bearing from this reference to the other, in degrees from true north. The distances
+// are calculated on a sphere (see LatLon.DistanceTo), so differ from the grid distance by the
+// projection's scale factor as well as the spherical approximation. If the references are the
+// same, both distances are 0 and, as there is no route, the bearing is NaN (see
+// LatLon.InitialBearingTo).
+func (o OsGridRef) RouteStats(other OsGridRef) (greatCircle, rhumb, bearing float64) {
+	from, to := o.ToLatLonStruct(), other.ToLatLonStruct()
+	return from.DistanceTo(to), from.RhumbDistanceTo(to), from.InitialBearingTo(to)
 }
 
 // Equivalent to `StringN(8)`
@@ -223,23 +468,87 @@ func (o OsGridRef) String() string {
 	return o.StringN(8)
 }
 
+// RoundingMode selects how a grid reference is reduced to the precision of a formatted reference.
+type RoundingMode int
+
+const (
+	// RoundTruncate gives the south-west corner of the cell containing the point, as is conventional
+	// for grid references.
+	RoundTruncate RoundingMode = iota
+	// RoundNearest gives the nearest cell corner, rounding halves up (i.e. north or east).
+	RoundNearest
+	// RoundHalfEven gives the nearest cell corner, rounding halves to an even number of cells.
+	RoundHalfEven
+)
+
 // Returns a string representation in the normal grid-letter format, with the requested number of
 // digits in the numeric part. The string will not contain any spaces.
 func (o OsGridRef) StringNCompact(digits int) string {
-	return o.stringN(digits, false)
+	return o.stringN(digits, false, RoundTruncate)
 }
 
 // Returns a string representation in the normal grid-letter format, with the requested number of
 // digits in the numeric part. The grid letters, easting and northing parts will be separated by spaces.
 func (o OsGridRef) StringN(digits int) string {
-	return o.stringN(digits, true)
+	return o.stringN(digits, true, RoundTruncate)
 }
 
-func (o OsGridRef) stringN(digits int, spaces bool) string {
+// StringNMode is equivalent to StringN, but reduces the reference to the requested precision using
+// the given rounding mode; StringN always truncates. Rounding may carry the reference into the next
+// 100km square.
+func (o OsGridRef) StringNMode(digits int, mode RoundingMode) string {
+	return o.stringN(digits, true, mode)
+}
+
+func (o OsGridRef) stringN(digits int, spaces bool, mode RoundingMode) string {
+	cell := pow10(5 - digits/2)
+
+	o = OsGridRef{Easting: roundToCell(o.Easting, cell, mode), Northing: roundToCell(o.Northing, cell, mode)}
 	e, n := o.Easting, o.Northing
+	letterPair := o.letterPair()
+
+	// strip 100km-grid indices from easting & northing, and reduce precision
+	e = (e % 100000) / cell
+	n = (n % 100000) / cell
+
+	// pad eastings & northings with leading zeros
+	if spaces {
+		return fmt.Sprintf("%s %0*d %0*d", letterPair, digits/2, e, digits/2, n)
+	}
+	return fmt.Sprintf("%s%0*d%0*d", letterPair, digits/2, e, digits/2, n)
+}
+
+// pow10 returns 10ⁿ, for n >= 0.
+func pow10(n int) int {
+	ret := 1
+	for i := 0; i < n; i++ {
+		ret *= 10
+	}
+	return ret
+}
+
+// roundToCell rounds the (non-negative) easting or northing v to a multiple of cell metres.
+func roundToCell(v, cell int, mode RoundingMode) int {
+	r := v % cell
+	v -= r
+	switch mode {
+	case RoundNearest:
+		if 2*r >= cell {
+			v += cell
+		}
+	case RoundHalfEven:
+		if 2*r > cell || (2*r == cell && (v/cell)%2 == 1) {
+			v += cell
+		}
+	}
+	return v
+}
+
+// letterPair returns the two letters identifying the 100km grid square containing o.
+func (o OsGridRef) letterPair() string {
 	// get the 100km-grid indices
-	e100km := e / 100_000
-	n100km := n / 100_000
+	e100km := o.Easting / 100_000
+	n100km := o.Northing / 100_000
 
 	// translate those into numeric equivalents of the grid letters
 	l1 := (19 - n100km) - (19-n100km)%5 + (e100km+10)/5
@@ -252,25 +561,50 @@ func (o OsGridRef) stringN(digits int, spaces bool) string {
 	if l2 > 7 {
 		l2++
 	}
-	letterPair := string([]byte{byte(l1 + 'A'), byte(l2 + 'A')})
+	return string([]byte{byte(l1 + 'A'), byte(l2 + 'A')})
+}
 
-	pow := func(n int) int {
-		ret := 1
-		for i := 0; i < n; i++ {
-			ret *= 10
-		}
-		return ret
+// GridSquare returns the two letters (e.g. "SW") identifying the 100km OS grid square containing
+// the (WGS84) point ll. ok is false if the point lies outside the OS grid.
+func (ll LatLon) GridSquare() (square string, ok bool) {
+	o := LatLonEllipsoidalDatum{Lat: ll.Lat, Lon: ll.Lon, Datum: WGS84}.ToOsGridRef()
+	if !o.Valid() {
+		return "", false
 	}
+	return o.letterPair(), true
+}
 
-	// strip 100km-grid indices from easting & northing, and reduce precision
-	e = (e % 100000) / pow(5-digits/2)
-	n = (n % 100000) / pow(5-digits/2)
+// ToGridReference returns the grid reference of the (WGS84) point ll, with as many figures as give
+// the requested resolution: for example "SW 4676 2854" for 10m. The resolution must be a power of
+// ten from 1 to 100000 metres; at 100000 metres just the grid letters are returned. It is an error
+// for the point to lie outside the OS grid.
+func (ll LatLon) ToGridReference(resolutionMetres int) (string, error) {
+	digits := 10
+	for r := 1; r < resolutionMetres && digits > 0; r *= 10 {
+		digits -= 2
+	}
+	if resolutionMetres < 1 || resolutionMetres > 100_000 || resolutionMetres != pow10(5-digits/2) {
+		return "", fmt.Errorf("invalid grid reference resolution %dm: must be a power of ten from 1 to 100000", resolutionMetres)
+	}
 
-	// pad eastings & northings with leading zeros
-	if spaces {
-		return fmt.Sprintf("%s %0*d %0*d", letterPair, digits/2, e, digits/2, n)
+	o, err := LatLonEllipsoidalDatum{Lat: ll.Lat, Lon: ll.Lon, Datum: WGS84}.ToOsGridRefChecked()
+	if err != nil {
+		return "", err
 	}
-	return fmt.Sprintf("%s%0*d%0*d", letterPair, digits/2, e, digits/2, n)
+	if digits == 0 {
+		return o.letterPair(), nil
+	}
+	return o.StringN(digits), nil
+}
+
+// Tetrad returns the 10km grid reference and DINTY tetrad letter identifying the 2km square
+// containing o, e.g. "SP98Q". It returns "" if o is not within the grid (see Valid).
+func (o OsGridRef) Tetrad() string {
+	if !o.Valid() {
+		return ""
+	}
+	i := (o.Easting%10000)/2000*5 + (o.Northing%10000)/2000
+	return o.StringNCompact(2) + string(tetradLetters[i])
 }
 
 // Returns a string representation in Easting,Northing format.
@@ -278,7 +612,109 @@ func (o OsGridRef) NumericString() string {
 	return fmt.Sprintf("%d,%d", o.Easting, o.Northing)
 }
 
-func osgb36ToWGS84(lat, lon float64) (float64, float64) {
+// NumericStringDP is equivalent to NumericString, but with the easting and northing given to dp
+// decimal places (e.g. "544982.00,257869.00" for dp=2), for consistency with sub-metre data
+// formatted alongside it. A dp of 0 or less gives the same as NumericString.
+func (o OsGridRef) NumericStringDP(dp int) string {
+	if dp < 0 {
+		dp = 0
+	}
+	return fmt.Sprintf("%.*f,%.*f", dp, float64(o.Easting), dp, float64(o.Northing))
+}
+
+// GridLines returns the (WGS84) lat/lon polylines of the grid lines, spacingMetres apart, across the
+// whole extent of the grid, for drawing the National Grid on a map. eastingLines are lines of
+// constant easting, running south to north; northingLines are lines of constant northing, running
+// west to east. Vertices are placed every 10km, whatever the spacing, so that the lines render as
+// curves in geographic space; at that scale the lines are very nearly straight.
+//
+// Lines cover the whole grid, so the spacing must be at least 1km (giving some 180,000 vertices);
+// for a smaller spacing, nil is returned.
+func GridLines(spacingMetres int) (eastingLines, northingLines [][]LatLon) {
+	if spacingMetres < minGridLineSpacing {
+		return nil, nil
+	}
+
+	const maxEasting, maxNorthing = 700_000, 1_300_000
+	const step = gridLineSample
+
+	line := func(from, to OsGridRef, length int) []LatLon {
+		var vertices []LatLon
+		for d := 0; ; d += step {
+			if d > length {
+				d = length
+			}
+			o := OsGridRef{
+				Easting:  from.Easting + (to.Easting-from.Easting)*d/length,
+				Northing: from.Northing + (to.Northing-from.Northing)*d/length,
+			}
+			lat, lon := o.ToLatLon()
+			vertices = append(vertices, LatLon{Lat: lat, Lon: lon})
+			if d == length {
+				return vertices
+			}
+		}
+	}
+
+	for e := 0; e <= maxEasting; e += spacingMetres {
+		eastingLines = append(eastingLines, line(OsGridRef{Easting: e}, OsGridRef{Easting: e, Northing: maxNorthing}, maxNorthing))
+	}
+	for n := 0; n <= maxNorthing; n += spacingMetres {
+		northingLines = append(northingLines, line(OsGridRef{Northing: n}, OsGridRef{Easting: maxEasting, Northing: n}, maxEasting))
+	}
+
+	return eastingLines, northingLines
+}
+
+// CellsInBox returns the south-west corners of the grid cells of cellSizeMetres covering the WGS84
+// lat/lon box with south-west corner sw and north-east corner ne, clipped to the grid, in rows from
+// south to north and west to east within each row. Cells are aligned to the grid, so a cell size of
+// 1000 gives the 1km squares of 4-figure grid references.
+//
+// A lat/lon box is not rectangular on the grid, so the cells are those covering the grid rectangle
+// enclosing it; cells near the corners may lie just outside the box itself.
+func CellsInBox(sw, ne LatLon, cellSizeMetres int) []OsGridRef {
+	if cellSizeMetres <= 0 {
+		return nil
+	}
+
+	const maxEasting, maxNorthing = 700_000, 1_300_000
+	const edgeSamples = 8 // parallels curve on the grid, so sample along each edge, not just corners
+
+	minE, minN := math.Inf(1), math.Inf(1)
+	maxE, maxN := math.Inf(-1), math.Inf(-1)
+	for i := 0; i <= edgeSamples; i++ {
+		f := float64(i) / edgeSamples
+		lat := sw.Lat + (ne.Lat-sw.Lat)*f
+		lon := sw.Lon + (ne.Lon-sw.Lon)*f
+		for _, p := range []LatLon{{Lat: sw.Lat, Lon: lon}, {Lat: ne.Lat, Lon: lon}, {Lat: lat, Lon: sw.Lon}, {Lat: lat, Lon: ne.Lon}} {
+			osgb := p.ConvertDatum(WGS84, OSGB36)
+			e, n := nationalGrid.Forward(osgb.Lat, osgb.Lon)
+			minE, maxE = math.Min(minE, e), math.Max(maxE, e)
+			minN, maxN = math.Min(minN, n), math.Max(maxN, n)
+		}
+	}
+
+	// clip to the grid, then align to cells
+	minE, maxE = math.Max(minE, 0), math.Min(maxE, maxEasting-1)
+	minN, maxN = math.Max(minN, 0), math.Min(maxN, maxNorthing-1)
+	if minE > maxE || minN > maxN {
+		return nil
+	}
+	e0 := int(minE) / cellSizeMetres * cellSizeMetres
+	n0 := int(minN) / cellSizeMetres * cellSizeMetres
+
+	var cells []OsGridRef
+	for n := n0; n <= int(maxN); n += cellSizeMetres {
+		for e := e0; e <= int(maxE); e += cellSizeMetres {
+			cells = append(cells, OsGridRef{Easting: e, Northing: n})
+		}
+	}
+
+	return cells
+}
+
+func osgb36To(lat, lon float64, datum Datum) (float64, float64) {
 	latLon := LatLonEllipsoidalDatum{
 		Lat:    lat,
 		Lon:    lon,
@@ -286,6 +722,21 @@ func osgb36ToWGS84(lat, lon float64) (float64, float64) {
 		Datum:  OSGB36,
 	}
 
-	converted := latLon.ConvertDatum(WGS84)
+	converted := latLon.ConvertDatum(datum)
+	return converted.Lat, converted.Lon
+}
+
+// OSGB36ToWGS84 converts an OSGB36 latitude/longitude (degrees), such as one read from an
+// older Ordnance Survey map, to WGS84 using the Helmert transformation of ConvertDatum. The
+// transformation is accurate to around 5 metres.
+func OSGB36ToWGS84(lat, lon float64) (float64, float64) {
+	return osgb36To(lat, lon, WGS84)
+}
+
+// WGS84ToOSGB36 converts a WGS84 latitude/longitude (degrees) to OSGB36; it is the inverse of
+// OSGB36ToWGS84 (to within a centimetre or so, as the Helmert transformations are not exact
+// inverses).
+func WGS84ToOSGB36(lat, lon float64) (float64, float64) {
+	converted := LatLonEllipsoidalDatum{Lat: lat, Lon: lon, Datum: WGS84}.ConvertDatum(OSGB36)
 	return converted.Lat, converted.Lon
 }
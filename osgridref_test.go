@@ -1,14 +1,86 @@
 package osgridref
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestNewOsGridRef(t *testing.T) {
+	tests := []struct {
+		name              string
+		easting, northing int
+		wantErr           bool
+	}{
+		{name: "SJ 92395 52997", easting: 392395, northing: 352997},
+		{name: "origin", easting: 0, northing: 0},
+		{name: "north-east corner", easting: 700000, northing: 1300000},
+		{name: "negative easting", easting: -1, northing: 352997, wantErr: true},
+		{name: "negative northing", easting: 392395, northing: -1, wantErr: true},
+		{name: "too far east", easting: 700001, northing: 352997, wantErr: true},
+		{name: "too far north", easting: 392395, northing: 1300001, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewOsGridRef(tt.easting, tt.northing)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, OsGridRef{}, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, OsGridRef{Easting: tt.easting, Northing: tt.northing}, got)
+		})
+	}
+}
+
+func TestRandomOsGridRef(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	minE, maxE, minN, maxN := 700_000, 0, 1_300_000, 0
+	for i := 0; i < 1000; i++ {
+		o := RandomOsGridRef(r)
+		require.True(t, o.Valid(), "%v", o)
+		if o.Easting < minE {
+			minE = o.Easting
+		}
+		if o.Easting > maxE {
+			maxE = o.Easting
+		}
+		if o.Northing < minN {
+			minN = o.Northing
+		}
+		if o.Northing > maxN {
+			maxN = o.Northing
+		}
+
+		// round trip through lat/lon to within the metre
+		ll := LatLonEllipsoidalDatum{Datum: WGS84}
+		ll.Lat, ll.Lon = o.ToLatLon()
+		got := ll.ToOsGridRef()
+		require.True(t, got.EqualsWithin(o, 1), "%v round trips to %v", o, got)
+	}
+
+	// spread across the grid
+	assert.Less(t, minE, 10_000)
+	assert.Greater(t, maxE, 690_000)
+	assert.Less(t, minN, 10_000)
+	assert.Greater(t, maxN, 1_290_000)
+
+	r = rand.New(rand.NewSource(1))
+	o := RandomOsGridRef(r)
+	r = rand.New(rand.NewSource(1))
+	assert.Equal(t, o.ToLatLonStruct(), RandomLatLonInGB(r))
+}
+
 func TestOsGridRef_toLatLon(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -92,6 +164,321 @@ func TestOsGridRef_toLatLon(t *testing.T) {
 	}
 }
 
+// TestOsGridRef_ToLatLon_OttoParity checks ToLatLon against the reference JS implementation at points
+// throughout every 100km square of the grid. Before the meridional arc coefficients were corrected
+// (see meridionalArc) latitudes here differed from the JS by up to 3m (about 0.00003°).
+func TestOsGridRef_ToLatLon_OttoParity(t *testing.T) {
+	offsets := []OsGridRef{
+		{Easting: 1, Northing: 1},
+		{Easting: 50_000, Northing: 50_000},
+		{Easting: 99_999, Northing: 12_345},
+	}
+	for e := 0; e < 700_000; e += 100_000 {
+		for n := 0; n < 1_300_000; n += 100_000 {
+			for _, offset := range offsets {
+				o := OsGridRef{Easting: e + offset.Easting, Northing: n + offset.Northing}
+				gridRef := o.StringN(10)
+				t.Run(gridRef, func(t *testing.T) {
+					lat, lon := o.ToLatLon()
+					jsLat, jsLon, err := OttoGridToLatLon(gridRef)
+					require.NoError(t, err)
+					assert.InDelta(t, jsLat, lat, 1e-7)
+					assert.InDelta(t, jsLon, lon, 1e-7)
+				})
+			}
+		}
+	}
+}
+
+func TestOsGridRef_ToLatLonTol(t *testing.T) {
+	tests := []struct {
+		name          string
+		gridRef       OsGridRef
+		tolerance     float64
+		maxIterations int
+	}{
+		{name: "SJ 92395 52997", gridRef: OsGridRef{Easting: 392395, Northing: 352997}, tolerance: defaultTolerance, maxIterations: 5},
+		{name: "NJ9439206608", gridRef: OsGridRef{Easting: 394392, Northing: 806608}, tolerance: defaultTolerance, maxIterations: 5},
+		{name: "SW4676028548", gridRef: OsGridRef{Easting: 146760, Northing: 28548}, tolerance: defaultTolerance, maxIterations: 5},
+		{name: "coarse", gridRef: OsGridRef{Easting: 394392, Northing: 806608}, tolerance: 1, maxIterations: 3},
+		{name: "zero tolerance", gridRef: OsGridRef{Easting: 394392, Northing: 806608}, tolerance: 0, maxIterations: maxIterations},
+		{name: "NaN tolerance", gridRef: OsGridRef{Easting: 394392, Northing: 806608}, tolerance: math.NaN(), maxIterations: maxIterations},
+		{name: "invalid", gridRef: OsGridRef{Easting: -1 << 40, Northing: 1 << 50}, tolerance: defaultTolerance, maxIterations: maxIterations},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lat, lon, iterations := tt.gridRef.toLatLon(tt.tolerance, WGS84)
+			assert.LessOrEqual(t, iterations, tt.maxIterations)
+
+			if tt.gridRef.Valid() {
+				wantLat, wantLon := tt.gridRef.ToLatLon()
+				assert.InDelta(t, wantLat, lat, 1e-5)
+				assert.InDelta(t, wantLon, lon, 1e-5)
+			}
+		})
+	}
+}
+
+func TestOsGridRef_ToLatLonStruct(t *testing.T) {
+	o, err := ParseOsGridRef("TL 44982 57869")
+	require.NoError(t, err)
+
+	lat, lon := o.ToLatLon()
+	assert.Equal(t, LatLon{Lat: lat, Lon: lon}, o.ToLatLonStruct())
+
+	// Cambridge, as given in the spherical tests
+	assert.InDelta(t, 561.0, o.ToLatLonStruct().DistanceTo(LatLon{Lat: 52.205, Lon: 0.119}), 0.1)
+}
+
+func TestOsGridRef_ToLatLonETRS89(t *testing.T) {
+	for _, gridRef := range []string{"SJ 92395 52997", "TG 51409 13177", "ST1784076329", "NJ9439206608", "SW4676028548"} {
+		t.Run(gridRef, func(t *testing.T) {
+			o, err := ParseOsGridRef(gridRef)
+			assert.NoError(t, err)
+			lat, lon := o.ToLatLon()
+			etrsLat, etrsLon := o.ToLatLonETRS89()
+
+			// coincident with WGS84 at the 1-metre level (1e-5° is about a metre)
+			assert.InDelta(t, lat, etrsLat, 1e-5)
+			assert.InDelta(t, lon, etrsLon, 1e-5)
+		})
+	}
+}
+
+func TestOsGridRef_EqualsWithin(t *testing.T) {
+	o := OsGridRef{Easting: 392395, Northing: 352997}
+	tests := []struct {
+		name   string
+		other  OsGridRef
+		metres int
+		want   bool
+	}{
+		{name: "identical", other: o, metres: 0, want: true},
+		{name: "3m east at 5m", other: OsGridRef{Easting: 392398, Northing: 352997}, metres: 5, want: true},
+		{name: "3m south at 5m", other: OsGridRef{Easting: 392395, Northing: 352994}, metres: 5, want: true},
+		{name: "3m east at 2m", other: OsGridRef{Easting: 392398, Northing: 352997}, metres: 2, want: false},
+		{name: "4m east 4m north at 5m", other: OsGridRef{Easting: 392399, Northing: 353001}, metres: 5, want: false},
+		{name: "3m east 4m north at 5m", other: OsGridRef{Easting: 392398, Northing: 353001}, metres: 5, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, o.EqualsWithin(tt.other, tt.metres))
+			assert.Equal(t, tt.want, tt.other.EqualsWithin(o, tt.metres))
+		})
+	}
+}
+
+func TestOsGridRef_Truncate(t *testing.T) {
+	o := OsGridRef{Easting: 392395, Northing: 352997}
+	assert.Equal(t, o, o.Truncate(1))
+	assert.Equal(t, OsGridRef{Easting: 392390, Northing: 352990}, o.Truncate(10))
+	assert.Equal(t, OsGridRef{Easting: 392000, Northing: 352000}, o.Truncate(1000))
+
+	fourFigure, precision, err := ParseOsGridRefWithPrecision("SJ 92 52")
+	assert.NoError(t, err)
+	assert.Equal(t, fourFigure, o.Truncate(precision))
+}
+
+func TestOsGridRef_CellCorners(t *testing.T) {
+	o, resolution, err := ParseOsGridRefWithPrecision("TL 4498 5786")
+	require.NoError(t, err)
+	require.Equal(t, 10, resolution)
+
+	corners := OsGridRef{Easting: 544982, Northing: 257869}.CellCorners(1000)
+	assert.Equal(t, [4]OsGridRef{
+		{Easting: 544000, Northing: 257000},
+		{Easting: 545000, Northing: 257000},
+		{Easting: 545000, Northing: 258000},
+		{Easting: 544000, Northing: 258000},
+	}, corners)
+	for i := range corners {
+		next := corners[(i+1)%4]
+		assert.True(t, corners[i].EqualsWithin(next, 1000) && !corners[i].EqualsWithin(next, 999), "%v to %v", corners[i], next)
+	}
+
+	// the cell denoted by a parsed reference
+	assert.Equal(t, o, o.CellCorners(resolution)[0])
+	assert.Equal(t, OsGridRef{Easting: 544990, Northing: 257870}, o.CellCorners(resolution)[2])
+
+	// 1m cells, and nonsensical resolutions
+	one := OsGridRef{Easting: 1, Northing: 2}
+	assert.Equal(t, [4]OsGridRef{{Easting: 1, Northing: 2}, {Easting: 2, Northing: 2}, {Easting: 2, Northing: 3}, {Easting: 1, Northing: 3}}, one.CellCorners(1))
+	assert.Equal(t, one.CellCorners(1), one.CellCorners(0))
+}
+
+func TestOsGridRef_Compare(t *testing.T) {
+	o := OsGridRef{Easting: 544982, Northing: 257869}
+	assert.Equal(t, 0, o.Compare(o))
+	assert.Equal(t, -1, o.Compare(OsGridRef{Easting: 100000, Northing: 257870}))
+	assert.Equal(t, +1, o.Compare(OsGridRef{Easting: 600000, Northing: 257868}))
+	assert.Equal(t, -1, o.Compare(OsGridRef{Easting: 544983, Northing: 257869}))
+	assert.Equal(t, +1, o.Compare(OsGridRef{Easting: 544981, Northing: 257869}))
+}
+
+func TestSortOsGridRefs(t *testing.T) {
+	want := []OsGridRef{
+		{Easting: 0, Northing: 0},
+		{Easting: 146760, Northing: 28548},
+		{Easting: 317840, Northing: 176329},
+		{Easting: 544982, Northing: 257869},
+		{Easting: 544983, Northing: 257869},
+		{Easting: 392395, Northing: 352997},
+		{Easting: 651409, Northing: 313177 + 100000},
+		{Easting: 394392, Northing: 806608},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		refs := append([]OsGridRef(nil), want...)
+		r.Shuffle(len(refs), func(i, j int) { refs[i], refs[j] = refs[j], refs[i] })
+		SortOsGridRefs(refs)
+		assert.Equal(t, want, refs)
+
+		// sorted, so can be binary searched
+		target := OsGridRef{Easting: 392395, Northing: 352997}
+		j := sort.Search(len(refs), func(j int) bool { return refs[j].Compare(target) >= 0 })
+		assert.Equal(t, target, refs[j])
+	}
+}
+
+func TestOsGridRef_FalseOrigin(t *testing.T) {
+	// the false origin is the south-west corner of square SV
+	origin := OsGridRef{Easting: 0, Northing: 0}
+	assert.Equal(t, "SV 00000 00000", origin.StringN(10))
+	assert.Equal(t, "SV 0000 0000", origin.String())
+	assert.Equal(t, "SV 00 00", origin.StringN(4))
+	assert.Equal(t, "SV0000000000", origin.StringNCompact(10))
+
+	for _, s := range []string{"SV 00000 00000", "SV0000000000", "SV 00 00", "0,0"} {
+		o, err := ParseOsGridRef(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, origin, o, s)
+	}
+
+	// along the south and west edges of the grid, and the last metre of SV
+	for _, tt := range []struct {
+		o    OsGridRef
+		want string
+	}{
+		{o: OsGridRef{Easting: 1, Northing: 0}, want: "SV 00001 00000"},
+		{o: OsGridRef{Easting: 0, Northing: 1}, want: "SV 00000 00001"},
+		{o: OsGridRef{Easting: 99999, Northing: 99999}, want: "SV 99999 99999"},
+		{o: OsGridRef{Easting: 100000, Northing: 0}, want: "SW 00000 00000"},
+		{o: OsGridRef{Easting: 0, Northing: 100000}, want: "SQ 00000 00000"},
+	} {
+		assert.Equal(t, tt.want, tt.o.StringN(10))
+		o, err := ParseOsGridRef(tt.want)
+		require.NoError(t, err, tt.want)
+		assert.Equal(t, tt.o, o, tt.want)
+	}
+}
+
+func TestOsGridRef_NumericStringDP(t *testing.T) {
+	tests := []struct {
+		o    OsGridRef
+		dp   int
+		want string
+	}{
+		{o: OsGridRef{Easting: 544982, Northing: 257869}, dp: 0, want: "544982,257869"},
+		{o: OsGridRef{Easting: 544982, Northing: 257869}, dp: 2, want: "544982.00,257869.00"},
+		{o: OsGridRef{Easting: 0, Northing: 0}, dp: 1, want: "0.0,0.0"},
+		{o: OsGridRef{Easting: 46760, Northing: 1028548}, dp: 3, want: "46760.000,1028548.000"},
+		{o: OsGridRef{Easting: 544982, Northing: 257869}, dp: -1, want: "544982,257869"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.o.NumericStringDP(tt.dp))
+			if tt.dp <= 0 {
+				assert.Equal(t, tt.o.NumericString(), tt.o.NumericStringDP(tt.dp))
+			}
+		})
+	}
+}
+
+func TestOsGridRef_GridConvergence(t *testing.T) {
+	// Expected values were computed independently of the OS series used here, from Karney's 6th-order
+	// Krüger series for the transverse Mercator projection (which reproduces the OS worked example,
+	// 52°39′27.2531″N 1°43′4.5177″E => 651409.903 313177.270, to 0.1mm), as the angle between the
+	// projected meridian and grid north. The OS series is truncated, so agreement is to about 1e-7°
+	// far from the central meridian.
+	tests := []struct {
+		gridRef string
+		want    float64
+	}{
+		{gridRef: "SJ 92395 52997", want: -0.090753549},
+		{gridRef: "TG 51409 13177", want: 2.957365835},
+		{gridRef: "SW4676028548", want: -2.718687468},
+		{gridRef: "NJ9439206608", want: -0.077876713},
+		{gridRef: "ND 30000 70000", want: -1.028755717},
+		{gridRef: "NB 10000 10000", want: -4.163707361},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gridRef, func(t *testing.T) {
+			o, err := ParseOsGridRef(tt.gridRef)
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, o.GridConvergence(), 1e-6)
+		})
+	}
+
+	// zero on the central meridian, and of opposite sign either side of it
+	assert.InDelta(t, 0, OsGridRef{Easting: 400000, Northing: 500000}.GridConvergence(), 1e-12)
+	assert.Greater(t, OsGridRef{Easting: 500000, Northing: 500000}.GridConvergence(), 0.0)
+	assert.Less(t, OsGridRef{Easting: 300000, Northing: 500000}.GridConvergence(), 0.0)
+}
+
+func TestOsGridRef_ScaleFactor(t *testing.T) {
+	// on the central meridian
+	assert.InDelta(t, F0, OsGridRef{Easting: 400000, Northing: 100000}.ScaleFactor(), 1e-12)
+	assert.InDelta(t, F0, OsGridRef{Easting: 400000, Northing: 1000000}.ScaleFactor(), 1e-12)
+
+	// increasing symmetrically towards the edges of the grid
+	assert.InDelta(t,
+		OsGridRef{Easting: 300000, Northing: 500000}.ScaleFactor(),
+		OsGridRef{Easting: 500000, Northing: 500000}.ScaleFactor(), 1e-12)
+	assert.Greater(t, OsGridRef{Easting: 600000, Northing: 500000}.ScaleFactor(), OsGridRef{Easting: 500000, Northing: 500000}.ScaleFactor())
+
+	// far east, compared against the scale factor on a sphere, k = F0 / √(1 − cos²φ⋅sin²Δλ)
+	for _, o := range []OsGridRef{{Easting: 651409, Northing: 313177}, {Easting: 700000, Northing: 300000}, {Easting: 146760, Northing: 28548}} {
+		ll := LatLonEllipsoidalDatum{Datum: WGS84}
+		ll.Lat, ll.Lon = o.ToLatLon()
+		ll = ll.ConvertDatum(OSGB36)
+		B := math.Cos(ll.Lat*toRadians) * math.Sin(ll.Lon*toRadians-λ0)
+		want := F0 / math.Sqrt(1-B*B)
+		assert.InDelta(t, want, o.ScaleFactor(), 1e-5, "%v", o)
+	}
+	assert.InDelta(t, 1.00070, OsGridRef{Easting: 700000, Northing: 300000}.ScaleFactor(), 1e-5)
+}
+
+func TestOsGridRef_RouteStats(t *testing.T) {
+	// 30km west and 40km north on the grid: 50km apart
+	cambridge := OsGridRef{Easting: 544982, Northing: 257869}
+	other := OsGridRef{Easting: 514982, Northing: 297869}
+
+	greatCircle, rhumb, bearing := cambridge.RouteStats(other)
+	assert.InDelta(t, 49930.432, greatCircle, 1e-3)
+	assert.InDelta(t, 49930.504, rhumb, 1e-3)
+	assert.InDelta(t, 324.8769, bearing, 1e-4)
+
+	// over this distance the rhumb line is barely longer than the great circle
+	assert.Greater(t, rhumb, greatCircle)
+	assert.Less(t, rhumb-greatCircle, 0.1)
+
+	// true bearing is grid bearing plus convergence (approximately, over 50km)
+	gridBearing := math.Atan2(-30, 40)*toDegrees + 360
+	assert.InDelta(t, gridBearing+cambridge.GridConvergence(), bearing, 0.1)
+
+	back, backRhumb, backBearing := other.RouteStats(cambridge)
+	assert.InDelta(t, greatCircle, back, 1e-6)
+	assert.InDelta(t, rhumb, backRhumb, 1e-6)
+	assert.InDelta(t, 144.5402, backBearing, 1e-4)
+
+	// identical references: no distance and no defined bearing
+	greatCircle, rhumb, bearing = cambridge.RouteStats(cambridge)
+	assert.Equal(t, 0.0, greatCircle)
+	assert.Equal(t, 0.0, rhumb)
+	assert.True(t, math.IsNaN(bearing))
+}
+
 func TestParseOsGridRef(t *testing.T) {
 	tests := []struct {
 		s       string
@@ -103,6 +490,34 @@ func TestParseOsGridRef(t *testing.T) {
 			want:    OsGridRef{Easting: 651409, Northing: 313177},
 			wantErr: false,
 		},
+		{
+			s:       "392395m,352997m",
+			want:    OsGridRef{Easting: 392395, Northing: 352997},
+			wantErr: false,
+		},
+		{
+			s:       "392395 M, 352997 m",
+			want:    OsGridRef{Easting: 392395, Northing: 352997},
+			wantErr: false,
+		},
+		{
+			s:       "392395metres, 352997Metres",
+			want:    OsGridRef{Easting: 392395, Northing: 352997},
+			wantErr: false,
+		},
+		{
+			s:       "392395m, 352997",
+			want:    OsGridRef{Easting: 392395, Northing: 352997},
+			wantErr: false,
+		},
+		{
+			s:       "392395km, 352997km",
+			wantErr: true,
+		},
+		{
+			s:       "392395mm, 352997mm",
+			wantErr: true,
+		},
 		{
 			s:       "TG 51409 13177",
 			want:    OsGridRef{Easting: 651409, Northing: 313177},
@@ -123,6 +538,19 @@ func TestParseOsGridRef(t *testing.T) {
 			want:    OsGridRef{Easting: 408490, Northing: 425580},
 			wantErr: false,
 		},
+		{
+			s:       "SJ 92395 52997",
+			want:    OsGridRef{Easting: 392395, Northing: 352997},
+			wantErr: false,
+		},
+		{
+			s:       "SJ 392395 352997",
+			wantErr: true,
+		},
+		{
+			s:       "SJ92395529971",
+			wantErr: true,
+		},
 		{
 			s:       "SI095255",
 			wantErr: true,
@@ -131,6 +559,26 @@ func TestParseOsGridRef(t *testing.T) {
 			s:       "ZZ095255",
 			wantErr: true,
 		},
+		{
+			s:    "HP 61 16",
+			want: OsGridRef{Easting: 461000, Northing: 1216000},
+		},
+		{
+			s:    "HW 6130 3230",
+			want: OsGridRef{Easting: 161300, Northing: 1032300},
+		},
+		{
+			s:       "HA 123 456",
+			wantErr: true,
+		},
+		{
+			s:       "JZ 123 456",
+			wantErr: true,
+		},
+		{
+			s:       "AA 123 456",
+			wantErr: true,
+		},
 		{
 			s:       "S095255",
 			wantErr: true,
@@ -158,6 +606,130 @@ func TestParseOsGridRef(t *testing.T) {
 	}
 }
 
+func TestParseOsGridRef_Separators(t *testing.T) {
+	want, err := ParseOsGridRef("SW 4676 2854")
+	require.NoError(t, err)
+
+	for _, s := range []string{"SW-4676-2854", "SW/4676/2854", "sw-4676 2854", "SW4676-2854", "SW 4676 / 2854"} {
+		got, precision, err := ParseOsGridRefWithPrecision(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+		assert.Equal(t, 10, precision, s)
+	}
+
+	got, err := ParseOsGridRef("SP-98/Q")
+	require.NoError(t, err)
+	assert.Equal(t, "SP98Q", got.Tetrad())
+
+	// not allowed in the comma-separated form
+	for _, s := range []string{"146760,-28548", "146760/28548,0", "SW-4676-285X"} {
+		_, err := ParseOsGridRef(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestParseOsGridRef_OutsideGrid(t *testing.T) {
+	// every valid square parses, and gives a reference within the grid
+	for e := 0; e < 700_000; e += 100_000 {
+		for n := 0; n < 1_300_000; n += 100_000 {
+			square := OsGridRef{Easting: e, Northing: n}.letterPair()
+			o, err := ParseOsGridRef(square + " 99999 99999")
+			require.NoError(t, err, square)
+			assert.True(t, o.Valid(), square)
+			assert.Equal(t, OsGridRef{Easting: e + 99999, Northing: n + 99999}, o)
+		}
+	}
+
+	for _, s := range []string{"HA 123 456", "JZ 123 456", "ZZ 123 456", "AV 123 456"} {
+		_, err := ParseOsGridRef(s)
+		assert.True(t, errors.Is(err, ErrOutOfRange), "%s: got %v", s, err)
+	}
+}
+
+func TestOsGridRef_ToLatLonRounded(t *testing.T) {
+	tests := []struct {
+		gridRef       string
+		decimalPlaces int
+		wantLat       float64
+		wantLon       float64
+	}{
+		{gridRef: "SW 46760 28548", decimalPlaces: 4, wantLat: 50.1029, wantLon: -5.5428},
+		{gridRef: "SW 46760 28548", decimalPlaces: 2, wantLat: 50.10, wantLon: -5.54},
+		{gridRef: "SW 46760 28548", decimalPlaces: 0, wantLat: 50, wantLon: -6},
+		{gridRef: "TL4498257869", decimalPlaces: 5, wantLat: 52.19999, wantLon: 0.11999},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%d", tt.gridRef, tt.decimalPlaces), func(t *testing.T) {
+			o, err := ParseOsGridRef(tt.gridRef)
+			assert.NoError(t, err)
+			lat, lon := o.ToLatLonRounded(tt.decimalPlaces)
+			assert.Equal(t, tt.wantLat, lat)
+			assert.Equal(t, tt.wantLon, lon)
+		})
+	}
+}
+
+func TestOsGridRef_ToLatLonWithAccuracy(t *testing.T) {
+	tests := []struct {
+		gridRef      string
+		wantAccuracy float64
+	}{
+		{gridRef: "TL4498257869", wantAccuracy: 5},
+		{gridRef: "TL 4498 5786", wantAccuracy: 10},
+		{gridRef: "TL 449 578", wantAccuracy: 100},
+		{gridRef: "TL 44 57", wantAccuracy: 1000},
+		{gridRef: "TL 4 5", wantAccuracy: 10000},
+		{gridRef: "TL 0 0", wantAccuracy: 100000},
+		{gridRef: "SV 0 0", wantAccuracy: 100000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.gridRef, func(t *testing.T) {
+			o, err := ParseOsGridRef(tt.gridRef)
+			require.NoError(t, err)
+			lat, lon, accuracy := o.ToLatLonWithAccuracy()
+			wantLat, wantLon := o.ToLatLon()
+			assert.Equal(t, wantLat, lat)
+			assert.Equal(t, wantLon, lon)
+			assert.Equal(t, tt.wantAccuracy, accuracy)
+		})
+	}
+
+	// a 1km reference is never reported as more accurate than 1km
+	o, err := ParseOsGridRef("SJ 92 52")
+	require.NoError(t, err)
+	_, _, accuracy := o.ToLatLonWithAccuracy()
+	assert.GreaterOrEqual(t, accuracy, 1000.0)
+}
+
+func TestParseOsGridRefWithPrecision(t *testing.T) {
+	tests := []struct {
+		s             string
+		want          OsGridRef
+		wantPrecision int
+		wantErr       bool
+	}{
+		{s: "ST 17 76", want: OsGridRef{Easting: 317000, Northing: 176000}, wantPrecision: 1000},
+		{s: "ST1784076329", want: OsGridRef{Easting: 317840, Northing: 176329}, wantPrecision: 1},
+		{s: "SU 0 0", want: OsGridRef{Easting: 400000, Northing: 100000}, wantPrecision: 10000},
+		{s: "SE095255", want: OsGridRef{Easting: 409500, Northing: 425500}, wantPrecision: 100},
+		{s: "SJ 9239 5299", want: OsGridRef{Easting: 392390, Northing: 352990}, wantPrecision: 10},
+		{s: "651409, 313177", want: OsGridRef{Easting: 651409, Northing: 313177}, wantPrecision: 1},
+		{s: "SJ95255", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, precision, err := ParseOsGridRefWithPrecision(tt.s)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPrecision, precision)
+		})
+	}
+}
+
 func Example() {
 
 	// Parse the OS Grid Reference for Newlyn Harbour
@@ -197,3 +769,308 @@ func Example() {
 	// 50.1029,-5.5428
 	// SW46762854
 }
+
+func TestLatLon_GridSquare(t *testing.T) {
+	tests := []struct {
+		name   string
+		ll     LatLon
+		want   string
+		wantOK bool
+	}{
+		{name: "Newlyn", ll: LatLon{Lat: 50.1029, Lon: -5.5428}, want: "SW", wantOK: true},
+		{name: "Cambridge", ll: LatLon{Lat: 52.2, Lon: 0.12}, want: "TL", wantOK: true},
+		{name: "Ben Nevis", ll: LatLon{Lat: 56.7969, Lon: -5.0036}, want: "NN", wantOK: true},
+		{name: "Paris", ll: LatLon{Lat: 48.8566, Lon: 2.3522}, wantOK: false},
+		{name: "Lyon", ll: LatLon{Lat: 45.764, Lon: 4.8357}, wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.ll.GridSquare()
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGridLines(t *testing.T) {
+	eastingLines, northingLines := GridLines(100_000)
+	require.Len(t, eastingLines, 8)
+	require.Len(t, northingLines, 14)
+
+	for _, line := range eastingLines {
+		assert.Len(t, line, 131)
+	}
+	for _, line := range northingLines {
+		assert.Len(t, line, 71)
+	}
+
+	// the vertices lie on the grid lines
+	lat, lon := OsGridRef{Easting: 400_000, Northing: 300_000}.ToLatLon()
+	assert.Equal(t, LatLon{Lat: lat, Lon: lon}, eastingLines[4][30])
+	assert.Equal(t, LatLon{Lat: lat, Lon: lon}, northingLines[3][40])
+	lat, lon = OsGridRef{Easting: 700_000, Northing: 1_300_000}.ToLatLon()
+	assert.Equal(t, LatLon{Lat: lat, Lon: lon}, eastingLines[7][130])
+
+	// spacing that doesn't divide the extent, finer than the sampling interval: vertices are still
+	// every 10km
+	eastingLines, northingLines = GridLines(3_000)
+	assert.Len(t, eastingLines, 234)
+	assert.Len(t, northingLines, 434)
+	assert.Len(t, eastingLines[1], 131)
+	assert.Len(t, northingLines[1], 71)
+	lat, lon = OsGridRef{Easting: 3_000, Northing: 20_000}.ToLatLon()
+	assert.Equal(t, LatLon{Lat: lat, Lon: lon}, eastingLines[1][2])
+
+	// the lines end at the edge of the grid
+	lat, lon = OsGridRef{Easting: 700_000, Northing: 3_000}.ToLatLon()
+	assert.Equal(t, LatLon{Lat: lat, Lon: lon}, northingLines[1][70])
+
+	eastingLines, northingLines = GridLines(1_000)
+	assert.Len(t, eastingLines, 701)
+	assert.Len(t, northingLines, 1301)
+
+	for _, spacing := range []int{0, -1000, 1, 999} {
+		eastingLines, northingLines = GridLines(spacing)
+		assert.Nil(t, eastingLines, "spacing %d", spacing)
+		assert.Nil(t, northingLines, "spacing %d", spacing)
+	}
+}
+
+// cancelAfterCtx is a context that becomes cancelled once Err has been called n times.
+type cancelAfterCtx struct {
+	context.Context
+	n int
+}
+
+func (c *cancelAfterCtx) Err() error {
+	if c.n--; c.n < 0 {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestParseOsGridRefsCtx(t *testing.T) {
+	refs := make([]string, 2500)
+	for i := range refs {
+		refs[i] = "TL 44982 57869"
+	}
+	refs[1] = "XX 123 456"
+	want, err := ParseOsGridRef(refs[0])
+	require.NoError(t, err)
+
+	gridRefs, errs := ParseOsGridRefsCtx(context.Background(), refs)
+	require.Len(t, gridRefs, len(refs))
+	require.Len(t, errs, len(refs))
+	assert.Error(t, errs[1])
+	for i := range refs {
+		if i != 1 {
+			assert.NoError(t, errs[i])
+			assert.Equal(t, want, gridRefs[i])
+		}
+	}
+
+	// Cancelled after the first 2000 items (i.e. on the third check).
+	gridRefs, errs = ParseOsGridRefsCtx(&cancelAfterCtx{Context: context.Background(), n: 2}, refs)
+	require.Len(t, gridRefs, len(refs))
+	assert.Equal(t, want, gridRefs[1999])
+	assert.NoError(t, errs[1999])
+	for i := 2000; i < len(refs); i++ {
+		assert.Equal(t, OsGridRef{}, gridRefs[i])
+		assert.Equal(t, context.Canceled, errs[i])
+	}
+
+	// Cancelled before starting.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	gridRefs, errs = ParseOsGridRefsCtx(ctx, refs[:3])
+	assert.Equal(t, []OsGridRef{{}, {}, {}}, gridRefs)
+	assert.Equal(t, []error{context.Canceled, context.Canceled, context.Canceled}, errs)
+}
+
+func TestOSGB36ToWGS84(t *testing.T) {
+	// Royal Observatory, Greenwich
+	lat, lon := OSGB36ToWGS84(51.4773642, 0.0001496)
+	assert.InDelta(t, 51.47788, lat, 1e-6)
+	assert.InDelta(t, -0.00147, lon, 1e-6)
+
+	lat, lon = WGS84ToOSGB36(51.47788, -0.00147)
+	assert.InDelta(t, 51.4773642, lat, 1e-7)
+	assert.InDelta(t, 0.0001496, lon, 1e-7)
+
+	// consistent with converting via a grid reference
+	o := OsGridRef{Easting: 544982, Northing: 257869}
+	lat, lon = OSGB36ToWGS84(NationalGrid().Inverse(float64(o.Easting), float64(o.Northing)))
+	wantLat, wantLon := o.ToLatLon()
+	assert.InDelta(t, wantLat, lat, 1e-9)
+	assert.InDelta(t, wantLon, lon, 1e-9)
+}
+
+func TestCellsInBox(t *testing.T) {
+	// Around Cambridge: 4 x 4 1km squares, TL4356 to TL4659.
+	cells := CellsInBox(LatLon{Lat: 52.19, Lon: 0.10}, LatLon{Lat: 52.21, Lon: 0.14}, 1000)
+	require.Len(t, cells, 16)
+	assert.Equal(t, "TL 43 56", cells[0].StringN(4))
+	assert.Equal(t, "TL 46 56", cells[3].StringN(4))
+	assert.Equal(t, "TL 43 57", cells[4].StringN(4))
+	assert.Equal(t, "TL 46 59", cells[15].StringN(4))
+	o, err := ParseOsGridRef("TL 44982 57869")
+	require.NoError(t, err)
+	assert.Contains(t, cells, o.Truncate(1000))
+
+	// One 100km square.
+	assert.Equal(t, []OsGridRef{{Easting: 500_000, Northing: 200_000}}, CellsInBox(LatLon{Lat: 52.19, Lon: 0.10}, LatLon{Lat: 52.21, Lon: 0.14}, 100_000))
+
+	// Clipped to the grid's south-west corner.
+	cells = CellsInBox(LatLon{Lat: 49.8, Lon: -8}, LatLon{Lat: 50.2, Lon: -7}, 10_000)
+	assert.Len(t, cells, 30)
+	assert.Equal(t, OsGridRef{}, cells[0])
+
+	assert.Empty(t, CellsInBox(LatLon{Lat: 40, Lon: 0}, LatLon{Lat: 41, Lon: 1}, 1000), "outside grid")
+	assert.Empty(t, CellsInBox(LatLon{Lat: 52.19, Lon: 0.10}, LatLon{Lat: 52.21, Lon: 0.14}, 0))
+}
+
+func TestParseLocation(t *testing.T) {
+	newlynLat, newlynLon := OsGridRef{Easting: 146760, Northing: 28548}.ToLatLon()
+
+	tests := []struct {
+		name     string
+		s        string
+		lat, lon float64
+		wantErr  bool
+	}{
+		{name: "grid ref", s: "SW 46760 28548", lat: newlynLat, lon: newlynLon},
+		{name: "compact grid ref", s: "sw4676028548", lat: newlynLat, lon: newlynLon},
+		{name: "easting,northing", s: "146760,28548", lat: newlynLat, lon: newlynLon},
+		{name: "lat,lon", s: "50.1029,-5.5428", lat: 50.1029, lon: -5.5428},
+		{name: "dms", s: "50°06′10″N 5°32′34″W", lat: 50.102778, lon: -5.542778},
+		{name: "whole degrees", s: "52,0", lat: 52, lon: 0},
+		{name: "garbage", s: "not a place", wantErr: true},
+		{name: "empty", s: "", wantErr: true},
+		{name: "bad grid letters", s: "XX 123 456", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLocation(tt.s)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.lat, got.Lat, 1e-6)
+			assert.InDelta(t, tt.lon, got.Lon, 1e-6)
+		})
+	}
+}
+
+func TestOsGridRef_StringNMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		o      OsGridRef
+		digits int
+		mode   RoundingMode
+		want   string
+	}{
+		{name: "truncate below half", o: OsGridRef{Easting: 544_499, Northing: 257_400}, digits: 6, mode: RoundTruncate, want: "TL 444 574"},
+		{name: "truncate above half", o: OsGridRef{Easting: 544_999, Northing: 257_999}, digits: 6, mode: RoundTruncate, want: "TL 449 579"},
+		{name: "nearest below half", o: OsGridRef{Easting: 544_449, Northing: 257_449}, digits: 6, mode: RoundNearest, want: "TL 444 574"},
+		{name: "nearest half, odd cell", o: OsGridRef{Easting: 544_350, Northing: 257_350}, digits: 6, mode: RoundNearest, want: "TL 444 574"},
+		{name: "nearest half, even cell", o: OsGridRef{Easting: 544_450, Northing: 257_450}, digits: 6, mode: RoundNearest, want: "TL 445 575"},
+		{name: "half even, odd cell", o: OsGridRef{Easting: 544_350, Northing: 257_350}, digits: 6, mode: RoundHalfEven, want: "TL 444 574"},
+		{name: "half even, even cell", o: OsGridRef{Easting: 544_450, Northing: 257_450}, digits: 6, mode: RoundHalfEven, want: "TL 444 574"},
+		{name: "half even above half", o: OsGridRef{Easting: 544_451, Northing: 257_451}, digits: 6, mode: RoundHalfEven, want: "TL 445 575"},
+		{name: "nearest carries into next square", o: OsGridRef{Easting: 599_950, Northing: 299_950}, digits: 6, mode: RoundNearest, want: "TG 000 000"},
+		{name: "full precision unchanged", o: OsGridRef{Easting: 544_982, Northing: 257_869}, digits: 10, mode: RoundNearest, want: "TL 44982 57869"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.o.StringNMode(tt.digits, tt.mode))
+		})
+	}
+
+	o := OsGridRef{Easting: 544_982, Northing: 257_869}
+	assert.Equal(t, o.StringN(6), o.StringNMode(6, RoundTruncate))
+}
+
+func TestLatLon_ToGridReference(t *testing.T) {
+	lat, lon := OsGridRef{Easting: 146760, Northing: 28548}.ToLatLon()
+	newlyn := LatLon{Lat: lat, Lon: lon}
+
+	tests := []struct {
+		name       string
+		ll         LatLon
+		resolution int
+		want       string
+		wantErr    bool
+	}{
+		{name: "1m", ll: newlyn, resolution: 1, want: "SW 46760 28548"},
+		{name: "10m", ll: newlyn, resolution: 10, want: "SW 4676 2854"},
+		{name: "100m", ll: newlyn, resolution: 100, want: "SW 467 285"},
+		{name: "1km", ll: newlyn, resolution: 1000, want: "SW 46 28"},
+		{name: "10km", ll: newlyn, resolution: 10_000, want: "SW 4 2"},
+		{name: "100km", ll: newlyn, resolution: 100_000, want: "SW"},
+		{name: "zero", ll: newlyn, resolution: 0, wantErr: true},
+		{name: "not a power of ten", ll: newlyn, resolution: 50, wantErr: true},
+		{name: "too coarse", ll: newlyn, resolution: 1_000_000, wantErr: true},
+		{name: "negative", ll: newlyn, resolution: -10, wantErr: true},
+		{name: "outside grid", ll: paris, resolution: 10, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.ll.ToGridReference(tt.resolution)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseOsGridRef_Tetrad(t *testing.T) {
+	tests := []struct {
+		s    string
+		want OsGridRef
+	}{
+		{s: "SP98Q", want: OsGridRef{Easting: 496000, Northing: 280000}},
+		{s: "SP98A", want: OsGridRef{Easting: 490000, Northing: 280000}},
+		{s: "SP98E", want: OsGridRef{Easting: 490000, Northing: 288000}},
+		{s: "SP98V", want: OsGridRef{Easting: 498000, Northing: 280000}},
+		{s: "SP98Z", want: OsGridRef{Easting: 498000, Northing: 288000}},
+		{s: "SP98N", want: OsGridRef{Easting: 494000, Northing: 286000}},
+		{s: "tl 45 p", want: OsGridRef{Easting: 544000, Northing: 258000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, precision, err := ParseOsGridRefWithPrecision(tt.s)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, 2000, precision)
+		})
+	}
+
+	for _, s := range []string{"SP98O", "SP9Q", "SP9876Q", "SP98QQ", "XX98Q"} {
+		_, err := ParseOsGridRef(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestOsGridRef_Tetrad(t *testing.T) {
+	assert.Equal(t, "SP98Q", OsGridRef{Easting: 496000, Northing: 280000}.Tetrad())
+	assert.Equal(t, "SP98Q", OsGridRef{Easting: 497999, Northing: 281999}.Tetrad())
+	assert.Equal(t, "TL45N", OsGridRef{Easting: 544982, Northing: 257869}.Tetrad())
+	assert.Equal(t, "SV00A", OsGridRef{}.Tetrad())
+
+	// outside the grid
+	assert.Equal(t, "", OsGridRef{Easting: -3000, Northing: 5000}.Tetrad())
+	assert.Equal(t, "", OsGridRef{Easting: 5000, Northing: -1}.Tetrad())
+	assert.Equal(t, "", OsGridRef{Easting: 5000, Northing: 1300001}.Tetrad())
+
+	// round trip every tetrad of a 10km square
+	for _, letter := range tetradLetters {
+		s := "SP98" + string(letter)
+		o, err := ParseOsGridRef(s)
+		require.NoError(t, err)
+		assert.Equal(t, s, o.Tetrad())
+	}
+}
@@ -0,0 +1,49 @@
+package osgridref
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// TimedFix is a position recorded at a particular time, such as a GPS fix in a track.
+type TimedFix struct {
+	At  time.Time
+	Pos LatLon
+}
+
+// InterpolateAt returns the position at time t along the track given by fixes, which must be in
+// time order. The position is found on the great circle between the fixes either side of t, at the
+// fraction of the way between them given by the time. It returns false if t is before the first
+// fix or after the last.
+func InterpolateAt(fixes []TimedFix, t time.Time) (LatLon, bool) {
+	if len(fixes) == 0 || t.Before(fixes[0].At) || t.After(fixes[len(fixes)-1].At) {
+		return LatLon{}, false
+	}
+
+	// first fix not before t
+	i := sort.Search(len(fixes), func(i int) bool { return !fixes[i].At.Before(t) })
+	if fixes[i].At.Equal(t) {
+		return fixes[i].Pos, true
+	}
+
+	from, to := fixes[i-1], fixes[i]
+	fraction := float64(t.Sub(from.At)) / float64(to.At.Sub(from.At))
+
+	return from.Pos.IntermediatePointTo(to.Pos, fraction), true
+}
+
+// VelocityTo returns the average speed, in metres per second, and the initial bearing, in degrees
+// from north, of travel along the great circle from fix a to fix b. If b is not later than a, the
+// speed is undefined and NaN is returned for it; likewise the bearing is NaN if the positions
+// coincide.
+func (a TimedFix) VelocityTo(b TimedFix) (speedMetresPerSec, bearing float64) {
+	bearing = a.Pos.InitialBearingTo(b.Pos)
+
+	elapsed := b.At.Sub(a.At).Seconds()
+	if elapsed <= 0 {
+		return math.NaN(), bearing
+	}
+
+	return a.Pos.DistanceTo(b.Pos) / elapsed, bearing
+}
@@ -0,0 +1,82 @@
+package osgridref
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolateAt(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	fixes := []TimedFix{
+		{At: start, Pos: cambridge},
+		{At: start.Add(10 * time.Minute), Pos: cambridge.DestinationPoint(10_000, 90)},
+		{At: start.Add(30 * time.Minute), Pos: cambridge.DestinationPoint(10_000, 90).DestinationPoint(4_000, 0)},
+	}
+
+	tests := []struct {
+		name   string
+		t      time.Time
+		want   LatLon
+		wantOK bool
+	}{
+		{name: "first fix", t: start, want: fixes[0].Pos, wantOK: true},
+		{name: "midpoint", t: start.Add(5 * time.Minute), want: cambridge.MidpointTo(fixes[1].Pos), wantOK: true},
+		{name: "at fix", t: start.Add(10 * time.Minute), want: fixes[1].Pos, wantOK: true},
+		{name: "quarter way", t: start.Add(15 * time.Minute), want: fixes[1].Pos.IntermediatePointTo(fixes[2].Pos, 0.25), wantOK: true},
+		{name: "last fix", t: start.Add(30 * time.Minute), want: fixes[2].Pos, wantOK: true},
+		{name: "before", t: start.Add(-time.Second), wantOK: false},
+		{name: "after", t: start.Add(31 * time.Minute), wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := InterpolateAt(fixes, tt.t)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.InDelta(t, tt.want.Lat, got.Lat, 1e-9)
+			assert.InDelta(t, tt.want.Lon, got.Lon, 1e-9)
+		})
+	}
+
+	// half way in time is half way in distance
+	mid, ok := InterpolateAt(fixes, start.Add(5*time.Minute))
+	assert.True(t, ok)
+	assert.InDelta(t, 5_000, cambridge.DistanceTo(mid), 1e-6)
+
+	_, ok = InterpolateAt(nil, start)
+	assert.False(t, ok)
+}
+
+func TestTimedFix_VelocityTo(t *testing.T) {
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	a := TimedFix{At: start, Pos: cambridge}
+
+	tests := []struct {
+		name        string
+		b           TimedFix
+		wantSpeed   float64
+		wantBearing float64
+	}{
+		{name: "10m east in 1s", b: TimedFix{At: start.Add(time.Second), Pos: cambridge.DestinationPoint(10, 90)}, wantSpeed: 10, wantBearing: 90},
+		{name: "1km south-west in 100s", b: TimedFix{At: start.Add(100 * time.Second), Pos: cambridge.DestinationPoint(1000, 225)}, wantSpeed: 10, wantBearing: 225},
+		{name: "stationary", b: TimedFix{At: start.Add(time.Minute), Pos: cambridge}, wantSpeed: 0, wantBearing: math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			speed, bearing := a.VelocityTo(tt.b)
+			assert.InDelta(t, tt.wantSpeed, speed, 1e-6)
+			if math.IsNaN(tt.wantBearing) {
+				assert.True(t, math.IsNaN(bearing), "got %v", bearing)
+				return
+			}
+			assert.InDelta(t, tt.wantBearing, bearing, 1e-6)
+		})
+	}
+
+	speed, bearing := a.VelocityTo(TimedFix{At: start, Pos: cambridge.DestinationPoint(10, 90)})
+	assert.True(t, math.IsNaN(speed), "zero time delta")
+	assert.InDelta(t, 90, bearing, 1e-6)
+	speed, _ = a.VelocityTo(TimedFix{At: start.Add(-time.Second), Pos: cambridge.DestinationPoint(10, 90)})
+	assert.True(t, math.IsNaN(speed), "negative time delta")
+}
@@ -0,0 +1,219 @@
+package osgridref
+
+import "math"
+
+// TransverseMercator is a transverse Mercator projection of an ellipsoid, using the formulae of
+// the Ordnance Survey ‘A guide to coordinate systems in Great Britain’, Annex C. Latitudes and
+// longitudes are on the projection's own datum; any datum conversion is up to the caller.
+type TransverseMercator struct {
+	Ellipsoid Ellipseoid
+	F0        float64 // scale factor on central meridian
+	Lat0      float64 // latitude of true origin, degrees
+	Lon0      float64 // longitude of true origin (i.e. central meridian), degrees
+	E0        float64 // easting of true origin, metres
+	N0        float64 // northing of true origin, metres
+}
+
+// nationalGrid is the projection of OSGB36 latitude/longitude used by the OS National Grid.
+var nationalGrid = TransverseMercator{
+	Ellipsoid: ellipsoids["Airy1830"],
+	F0:        F0,
+	Lat0:      49,
+	Lon0:      -2,
+	E0:        E0,
+	N0:        N0,
+}
+
+// NationalGrid returns the projection of OSGB36 latitude/longitude used by the OS National Grid.
+// It is a copy, so changing it does not affect the package's own conversions.
+func NationalGrid() TransverseMercator {
+	return nationalGrid
+}
+
+// Forward projects the latitude/longitude (degrees) to easting/northing (metres).
+func (tm TransverseMercator) Forward(lat, lon float64) (e, n float64) {
+	a, F0, e2 := tm.Ellipsoid.a, tm.F0, tm.e2()
+	φ := lat * toRadians
+	λ := lon * toRadians
+	λ0 := tm.Lon0 * toRadians
+
+	cosφ := math.Cos(φ)
+	sinφ := math.Sin(φ)
+	ν := a * F0 / math.Sqrt(1-e2*sinφ*sinφ)                // nu = transverse radius of curvature
+	ρ := a * F0 * (1 - e2) / math.Pow(1-e2*sinφ*sinφ, 1.5) // rho = meridional radius of curvature
+	η2 := ν/ρ - 1                                          // eta = ?
+
+	M := tm.meridionalArc(φ)
+
+	cos3φ := cosφ * cosφ * cosφ
+	cos5φ := cos3φ * cosφ * cosφ
+	tan2φ := math.Tan(φ) * math.Tan(φ)
+	tan4φ := tan2φ * tan2φ
+
+	I := M + tm.N0
+	II := (ν / 2) * sinφ * cosφ
+	III := (ν / 24) * sinφ * cos3φ * (5 - tan2φ + 9*η2)
+	IIIA := (ν / 720) * sinφ * cos5φ * (61 - 58*tan2φ + tan4φ)
+	IV := ν * cosφ
+	V := (ν / 6) * cos3φ * (ν/ρ - tan2φ)
+	VI := (ν / 120) * cos5φ * (5 - 18*tan2φ + tan4φ + 14*η2 - 58*tan2φ*η2)
+
+	Δλ := λ - λ0
+	Δλ2 := Δλ * Δλ
+	Δλ3 := Δλ2 * Δλ
+	Δλ4 := Δλ3 * Δλ
+	Δλ5 := Δλ4 * Δλ
+	Δλ6 := Δλ5 * Δλ
+
+	n = I + II*Δλ2 + III*Δλ4 + IIIA*Δλ6
+	e = tm.E0 + IV*Δλ + V*Δλ3 + VI*Δλ5
+
+	return e, n
+}
+
+// Inverse converts the easting/northing (metres) back to latitude/longitude (degrees).
+func (tm TransverseMercator) Inverse(e, n float64) (lat, lon float64) {
+	lat, lon, _ = tm.inverse(e, n, defaultTolerance)
+	return lat, lon
+}
+
+// inverse is Inverse, calculating the footpoint latitude to within tolMetres, and also returning
+// the number of iterations that took.
+func (tm TransverseMercator) inverse(e, n, tolMetres float64) (lat, lon float64, iterations int) {
+	a, F0, e2 := tm.Ellipsoid.a, tm.F0, tm.e2()
+	λ0 := tm.Lon0 * toRadians
+
+	φ, iterations := tm.footpointLatitude(n, tolMetres)
+
+	cosφ := math.Cos(φ)
+	sinφ := math.Sin(φ)
+	ν := a * F0 / math.Sqrt(1-e2*sinφ*sinφ)                // nu = transverse radius of curvature
+	ρ := a * F0 * (1 - e2) / math.Pow(1-e2*sinφ*sinφ, 1.5) // rho = meridional radius of curvature
+	η2 := ν/ρ - 1                                          // eta = ?
+
+	tanφ := math.Tan(φ)
+	tan2φ := tanφ * tanφ
+	tan4φ := tan2φ * tan2φ
+	tan6φ := tan4φ * tan2φ
+	secφ := 1 / cosφ
+	ν3 := ν * ν * ν
+	ν5 := ν3 * ν * ν
+	ν7 := ν5 * ν * ν
+	VII := tanφ / (2 * ρ * ν)
+	VIII := tanφ / (24 * ρ * ν3) * (5 + 3*tan2φ + η2 - 9*tan2φ*η2)
+	IX := tanφ / (720 * ρ * ν5) * (61 + 90*tan2φ + 45*tan4φ)
+	X := secφ / ν
+	XI := secφ / (6 * ν3) * (ν/ρ + 2*tan2φ)
+	XII := secφ / (120 * ν5) * (5 + 28*tan2φ + 24*tan4φ)
+	XIIA := secφ / (5040 * ν7) * (61 + 662*tan2φ + 1320*tan4φ + 720*tan6φ)
+
+	dE := e - tm.E0
+	dE2 := dE * dE
+	dE3 := dE2 * dE
+	dE4 := dE2 * dE2
+	dE5 := dE3 * dE2
+	dE6 := dE4 * dE2
+	dE7 := dE5 * dE2
+	φ = φ - VII*dE2 + VIII*dE4 - IX*dE6
+	λ := λ0 + X*dE - XI*dE3 + XII*dE5 - XIIA*dE7
+
+	return φ * toDegrees, λ * toDegrees, iterations
+}
+
+// convergence returns the grid convergence at the easting/northing, in degrees: the angle between
+// true north and grid north, positive east of the central meridian.
+func (tm TransverseMercator) convergence(e, n float64) float64 {
+	a, F0, e2 := tm.Ellipsoid.a, tm.F0, tm.e2()
+
+	φ, _ := tm.footpointLatitude(n, defaultTolerance)
+
+	sinφ := math.Sin(φ)
+	ν := a * F0 / math.Sqrt(1-e2*sinφ*sinφ)                // nu = transverse radius of curvature
+	ρ := a * F0 * (1 - e2) / math.Pow(1-e2*sinφ*sinφ, 1.5) // rho = meridional radius of curvature
+	η2 := ν/ρ - 1                                          // eta = ?
+
+	tanφ := math.Tan(φ)
+	tan2φ := tanφ * tanφ
+	tan4φ := tan2φ * tan2φ
+	ν3 := ν * ν * ν
+	ν5 := ν3 * ν * ν
+	XIII := tanφ / ν
+	XIV := tanφ / (3 * ν3) * (1 + tan2φ - η2 - 2*η2*η2)
+	XV := tanφ / (15 * ν5) * (2 + 5*tan2φ + 3*tan4φ)
+
+	dE := e - tm.E0
+	dE3 := dE * dE * dE
+	dE5 := dE3 * dE * dE
+	γ := XIII*dE - XIV*dE3 + XV*dE5
+
+	return γ * toDegrees
+}
+
+// scaleFactor returns the point scale factor of the projection at the easting/northing.
+func (tm TransverseMercator) scaleFactor(e, n float64) float64 {
+	a, F0, e2 := tm.Ellipsoid.a, tm.F0, tm.e2()
+
+	φ, _ := tm.footpointLatitude(n, defaultTolerance)
+
+	sinφ := math.Sin(φ)
+	ν := a * F0 / math.Sqrt(1-e2*sinφ*sinφ)                // nu = transverse radius of curvature
+	ρ := a * F0 * (1 - e2) / math.Pow(1-e2*sinφ*sinφ, 1.5) // rho = meridional radius of curvature
+	η2 := ν/ρ - 1                                          // eta = ?
+
+	XVI := 1 / (2 * ρ * ν)
+	XVII := (1 + 4*η2) / (24 * ρ * ρ * ν * ν)
+
+	dE := e - tm.E0
+	dE2 := dE * dE
+	dE4 := dE2 * dE2
+
+	return F0 * (1 + XVI*dE2 + XVII*dE4)
+}
+
+// footpointLatitude iteratively calculates the latitude φʹ (radians) at which the meridional arc
+// gives the northing n, to within tolMetres, also returning the number of iterations needed.
+func (tm TransverseMercator) footpointLatitude(n, tolMetres float64) (float64, int) {
+	φ := tm.Lat0 * toRadians
+	M := float64(0)
+
+	iterations := 0
+	for iterations < maxIterations {
+		iterations++
+		φ = (n-tm.N0-M)/(tm.Ellipsoid.a*tm.F0) + φ
+
+		M = tm.meridionalArc(φ)
+
+		// until within tolerance
+		if math.Abs(n-tm.N0-M) < tolMetres {
+			break
+		}
+	}
+
+	return φ, iterations
+}
+
+// meridionalArc returns the developed meridional arc, in metres, from the true origin's latitude
+// to latitude φ (radians).
+//
+// The fractional coefficients must be written as floating point constants: Go evaluates an untyped
+// constant expression such as 5/4 as integer division (giving 1), which in earlier versions put
+// latitudes out by up to 3m compared with the OS formulae and the JS reference implementation.
+func (tm TransverseMercator) meridionalArc(φ float64) float64 {
+	a, b := tm.Ellipsoid.a, tm.Ellipsoid.b
+	φ0 := tm.Lat0 * toRadians
+	n := (a - b) / (a + b)
+	n2 := n * n
+	n3 := n2 * n
+
+	Ma := (1 + n + (5.0/4)*n2 + (5.0/4)*n3) * (φ - φ0)
+	Mb := (3*n + 3*n*n + (21.0/8)*n3) * math.Sin(φ-φ0) * math.Cos(φ+φ0)
+	Mc := ((15.0/8)*n2 + (15.0/8)*n3) * math.Sin(2*(φ-φ0)) * math.Cos(2*(φ+φ0))
+	Md := (35.0 / 24) * n3 * math.Sin(3*(φ-φ0)) * math.Cos(3*(φ+φ0))
+	return b * tm.F0 * (Ma - Mb + Mc - Md)
+}
+
+// e2 returns the eccentricity squared of the projection's ellipsoid, (a²−b²)/a².
+func (tm TransverseMercator) e2() float64 {
+	a, b := tm.Ellipsoid.a, tm.Ellipsoid.b
+	return 1 - (b*b)/(a*a)
+}
@@ -0,0 +1,69 @@
+package osgridref
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNationalGrid(t *testing.T) {
+	// expected values as given by the National Grid formulae before they were extracted into
+	// TransverseMercator
+	tests := []struct {
+		o                        OsGridRef
+		lat, lon                 float64
+		convergence, scaleFactor float64
+	}{
+		{o: OsGridRef{Easting: 651409, Northing: 313177}, lat: 52.657568298087, lon: 1.717908051617, convergence: 2.957365855919131, scaleFactor: 1.000377309885529},
+		{o: OsGridRef{Easting: 146760, Northing: 28548}, lat: 50.102307314827, lon: -5.541818784765, convergence: -2.718687483153766, scaleFactor: 1.000389115761133},
+		{o: OsGridRef{Easting: 544982, Northing: 257869}, lat: 52.199557663378, lon: 0.121654034150, convergence: 1.676715571145244, scaleFactor: 0.999859355386561},
+		{o: OsGridRef{Easting: 392395, Northing: 352997}, lat: 53.073850914568, lon: -2.113525526523, convergence: -0.090753546872274, scaleFactor: 0.999601981650743},
+		{o: OsGridRef{Easting: 450000, Northing: 1200000}, lat: 60.680696391660, lon: -1.084606831999, convergence: 0.798151641808883, scaleFactor: 0.999631910123697},
+	}
+	for _, tt := range tests {
+		t.Run(tt.o.String(), func(t *testing.T) {
+			lat, lon := NationalGrid().Inverse(float64(tt.o.Easting), float64(tt.o.Northing))
+			assert.InDelta(t, tt.lat, lat, 1e-11)
+			assert.InDelta(t, tt.lon, lon, 1e-11)
+
+			e, n := NationalGrid().Forward(lat, lon)
+			assert.InDelta(t, float64(tt.o.Easting), e, 0.001)
+			assert.InDelta(t, float64(tt.o.Northing), n, 0.001)
+
+			assert.InDelta(t, tt.convergence, tt.o.GridConvergence(), 1e-12)
+			assert.InDelta(t, tt.scaleFactor, tt.o.ScaleFactor(), 1e-14)
+			assert.Equal(t, tt.o, LatLonEllipsoidalDatum{Lat: lat, Lon: lon, Datum: OSGB36}.ToOsGridRef())
+		})
+	}
+}
+
+func TestNationalGrid_Copy(t *testing.T) {
+	o := OsGridRef{Easting: 544982, Northing: 257869}
+	lat, lon := o.ToLatLon()
+
+	tm := NationalGrid()
+	tm.F0, tm.E0 = 1, 0
+	assert.NotEqual(t, tm, NationalGrid())
+
+	gotLat, gotLon := o.ToLatLon()
+	assert.Equal(t, lat, gotLat)
+	assert.Equal(t, lon, gotLon)
+}
+
+func TestTransverseMercator_UTM(t *testing.T) {
+	// UTM zone 31N
+	utm := TransverseMercator{Ellipsoid: WGS84.Ellipsoid, F0: 0.9996, Lat0: 0, Lon0: 3, E0: 500_000, N0: 0}
+
+	e, n := utm.Forward(48.8583, 2.2945) // Eiffel Tower
+	assert.InDelta(t, 448251.9, e, 0.1)
+	assert.InDelta(t, 5411943.8, n, 0.1)
+
+	lat, lon := utm.Inverse(e, n)
+	assert.InDelta(t, 48.8583, lat, 1e-9)
+	assert.InDelta(t, 2.2945, lon, 1e-9)
+
+	// on the central meridian at the equator
+	e, n = utm.Forward(0, 3)
+	assert.InDelta(t, 500_000, e, 1e-9)
+	assert.InDelta(t, 0, n, 1e-9)
+}
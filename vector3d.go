@@ -147,8 +147,8 @@ func (v Vector3d) AngleTo(other Vector3d, extraPlanar bool, n Vector3d) float64
 		sign = -1.0
 	}
 
-	sinθ := v.Cross(v).Length() * sign
-	cosθ := v.Dot(v)
+	sinθ := v.Cross(other).Length() * sign
+	cosθ := v.Dot(other)
 
 	return math.Atan2(sinθ, cosθ)
 }